@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/CodeMangler/degrees-of-separation/moviebuff"
+	"time"
+)
+
+// runCrawl fetches every entity within -depth hops of the seeds, so that later queries can be answered from the cache.
+func runCrawl(env *environment, args []string) int {
+	flags := env.newFlagSet("crawl", "crawl [flags] -seed <slug> [-seed <slug>...]")
+	seeds := &listFlag{}
+	flags.Var(seeds, "seed", "Moviebuff URL to start crawling from. Repeat, or separate with commas, for several seeds.")
+	depth := flags.Int("depth", 2, "Number of hops to crawl from the seeds. A person to a movie is one hop.")
+	timeout := flags.Duration("timeout", 0, "Stop crawling after this long, e.g. 30s or 2m. Crawls until done if 0.")
+	if code := env.parse(flags, args); code >= 0 {
+		return code
+	}
+	if len(*seeds) == 0 {
+		return env.usageError(errors.New("expected at least one -seed"))
+	}
+	if err := validateSlugs(*seeds); err != nil {
+		return env.usageError(err)
+	}
+	if *depth < 0 {
+		return env.usageError(errors.New("-depth must not be negative"))
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	nodeGroup := env.newNodeGroup(*depth)
+	entities, people, movies := 0, 0, 0
+	err := graph.Walk(ctx, env.nodes(nodeGroup, *seeds), *depth, func(node *graph.Node, depth int) {
+		env.logf("Crawled %v at depth %v", node, depth)
+		if entity, loaded := node.Data().(*moviebuff.Entity); loaded {
+			entities++
+			switch entity.Type {
+			case "Person":
+				people++
+			case "Movie":
+				movies++
+			}
+		}
+	})
+
+	loader := nodeGroup.Loader()
+	fmt.Fprintf(env.stdout, "Crawled %v entities (%v people, %v movies) with %v HTTP requests in %v\n",
+		entities, people, movies, env.client.Requests(), time.Since(start).Round(time.Millisecond))
+	if err == context.DeadlineExceeded {
+		fmt.Fprintf(env.stderr, "degrees: %v after %v, before crawling %v hops\n", errTimeout, *timeout, *depth)
+		return exitTimeout
+	}
+	if failed := loader.Failed(); failed > 0 {
+		fmt.Fprintf(env.stderr, "degrees: %v entities could not be fetched\n", failed)
+		return exitNetworkFailure
+	}
+	return exitConnected
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestCrawlFetchesEntitiesWithinDepth(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "crawl", "-seed", "amitabh-bachchan", "-depth", "1")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "Crawled 2 entities (1 people, 1 movies)")
+
+	code, stdout, _ = runDegrees("-data-dir", dir, "crawl", "-seed", "amitabh-bachchan,a-recluse", "-depth", "2")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "Crawled 4 entities (3 people, 1 movies)")
+}
+
+func TestCrawlValidatesUsage(t *testing.T) {
+	code, _, stderr := runDegrees("crawl")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "expected at least one -seed")
+
+	code, _, _ = runDegrees("crawl", "-seed", "Amitabh Bachchan")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runDegrees("crawl", "-seed", "amitabh-bachchan", "-depth", "-1")
+	assert.Equal(t, exitUsage, code)
+}
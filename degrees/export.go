@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/CodeMangler/degrees-of-separation/moviebuff"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const formatDOT = "dot"
+
+// runExport writes the graph within -depth hops of the seeds, for visualising with tools like Graphviz.
+func runExport(env *environment, args []string) int {
+	flags := env.newFlagSet("export", "export [flags] -seed <slug> [-seed <slug>...]")
+	seeds := &listFlag{}
+	flags.Var(seeds, "seed", "Moviebuff URL to export the graph around. Repeat, or separate with commas, for several seeds.")
+	depth := flags.Int("depth", 2, "Number of hops from the seeds to export. A person to a movie is one hop.")
+	format := flags.String("format", formatDOT, "Export format: dot.")
+	timeout := flags.Duration("timeout", 0, "Give up exporting after this long, e.g. 30s or 2m. Exports until done if 0.")
+	if code := env.parse(flags, args); code >= 0 {
+		return code
+	}
+	if len(*seeds) == 0 {
+		return env.usageError(errors.New("expected at least one -seed"))
+	}
+	if err := validateSlugs(*seeds); err != nil {
+		return env.usageError(err)
+	}
+	if *depth < 0 {
+		return env.usageError(errors.New("-depth must not be negative"))
+	}
+	if *format != formatDOT {
+		return env.usageError(fmt.Errorf("unknown export format %q", *format))
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	nodeGroup := env.newNodeGroup(*depth)
+	visited := make(map[string]*graph.Node)
+	err := graph.Walk(ctx, env.nodes(nodeGroup, *seeds), *depth, func(node *graph.Node, depth int) {
+		visited[node.ID] = node
+	})
+	if err == context.DeadlineExceeded {
+		fmt.Fprintf(env.stderr, "degrees: %v after %v, before exporting %v hops\n", errTimeout, *timeout, *depth)
+		return exitTimeout
+	}
+
+	writeDOT(env.stdout, visited)
+	if failed := nodeGroup.Loader().Failed(); failed > 0 {
+		fmt.Fprintf(env.stderr, "degrees: %v entities could not be fetched, so the export is incomplete\n", failed)
+		return exitNetworkFailure
+	}
+	return exitConnected
+}
+
+// writeDOT writes the given Nodes, and the credits connecting them to each other, as an undirected Graphviz graph.
+// Movies are drawn as boxes, people as ellipses, and each edge is labelled with the role connecting its two ends.
+func writeDOT(w io.Writer, nodes map[string]*graph.Node) {
+	fmt.Fprintln(w, "graph degrees {")
+	for _, node := range sortedNodes(nodes) {
+		shape := "ellipse"
+		if entity, loaded := node.Data().(*moviebuff.Entity); loaded && entity.Type == "Movie" {
+			shape = "box"
+		}
+		fmt.Fprintf(w, "  %v [label=%v, shape=%v];\n", strconv.Quote(node.ID), strconv.Quote(nameOf(node, "")), shape)
+	}
+
+	written := make(map[[2]string]bool)
+	for _, node := range sortedNodes(nodes) {
+		entity, loaded := node.Data().(*moviebuff.Entity)
+		if !loaded {
+			continue
+		}
+		for _, neighbour := range entity.Neighbours {
+			edge := [2]string{node.ID, neighbour.ID}
+			if neighbour.ID < node.ID {
+				edge = [2]string{neighbour.ID, node.ID}
+			}
+			if nodes[neighbour.ID] == nil || written[edge] {
+				continue
+			}
+			written[edge] = true
+			// The Edge's Credit has every role connecting the two, however many times the entities list each other
+			role := neighbour.Role
+			connection, _ := node.EdgeTo(nodes[neighbour.ID])
+			if credit, isCredit := connection.Data.(moviebuff.Credit); isCredit {
+				role = strings.Join(credit.Roles, ", ")
+			}
+			fmt.Fprintf(w, "  %v -- %v [label=%v];\n", strconv.Quote(node.ID), strconv.Quote(neighbour.ID), strconv.Quote(role))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestExportWritesDOT(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "export", "-seed", "amitabh-bachchan", "-depth", "2")
+	assert.Equal(t, exitConnected, code)
+	assert.Equal(t, `graph degrees {
+  "amitabh-bachchan" [label="Amitabh Bachchan", shape=ellipse];
+  "leonardo-dicaprio" [label="Leonardo DiCaprio", shape=ellipse];
+  "the-great-gatsby" [label="The Great Gatsby", shape=box];
+  "amitabh-bachchan" -- "the-great-gatsby" [label="Supporting Actor"];
+  "leonardo-dicaprio" -- "the-great-gatsby" [label="Actor"];
+}
+`, stdout)
+}
+
+func TestExportRejectsUnknownFormats(t *testing.T) {
+	code, _, stderr := runDegrees("export", "-seed", "amitabh-bachchan", "-format", "gexf")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "unknown export format")
+}
@@ -1,27 +1,305 @@
 package main
 
 import (
-	"../graph"
-	"../moviebuff"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/CodeMangler/degrees-of-separation/moviebuff"
+	"io"
+	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Exit codes
+const (
+	exitConnected      = 0
+	exitNotConnected   = 1
+	exitUsage          = 2
+	exitUnknownPerson  = 3
+	exitNetworkFailure = 4
+	exitTimeout        = 5
+)
+
+const (
+	sourceHTTP = "http"
+	sourceDir  = "dir"
+)
+
+const usage = `Usage: degrees [global flags] <command> [flags] [arguments]
+
+Explores how people in movies are connected, using Moviebuff data.
+People are identified by their Moviebuff URL: amitabh-bachchan for http://www.moviebuff.com/amitabh-bachchan
+
+Commands:
+  path <person> <person>  Find the smallest degree of separation between two people. The default command.
+  crawl -seed <slug>      Fetch every entity within -depth hops of the seeds, warming the cache.
+  stats                   Describe the entities in the cache.
+  export -seed <slug>     Export the graph within -depth hops of the seeds.
+  serve                   Answer path queries over HTTP.
+
+Exit codes:
+  0  Success. For path, the two people are connected
+  1  The two people are not connected within -max-depth degrees
+  2  Invalid usage
+  3  Unknown person
+  4  Network failure
+  5  Timed out
+
+Run 'degrees <command> -h' for the flags of a command, including the global flags.
+`
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// command runs a subcommand with its arguments, and returns its exit code.
+type command func(env *environment, args []string) int
+
+var commands = map[string]command{
+	"path":   runPath,
+	"crawl":  runCrawl,
+	"stats":  runStats,
+	"export": runExport,
+	"serve":  runServe,
+}
+
+// environment holds the global flags shared by every command, and the data source built from them.
+type environment struct {
+	stdout      io.Writer
+	stderr      io.Writer
+	source      string
+	dataDir     string
+	rate        float64
+	burst       int
+	cacheDir    string
+	cacheTTL    time.Duration
+	noCache     bool
+	concurrency int
+	verbose     bool
+	roleWeights string
+
+	weigh     moviebuff.RoleWeigher
+	minWeight float64
+
+	client  *moviebuff.Client
+	fetcher graph.ContextNodeFetcher
+}
+
 func main() {
-	sourceID := os.Args[1]
-	targetID := os.Args[2]
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	nodeGroup := graph.NewNodeGroup(4)
-	sourceNode := graph.NewNode(sourceID, graph.NodeFetcher(moviebuff.Fetch), nodeGroup)
-	targetNode := graph.NewNode(targetID, graph.NodeFetcher(moviebuff.Fetch), nodeGroup)
+// run runs the degrees command with the given arguments, and returns its exit code.
+// Global flags may come before or after the command name. Without a known command name, the arguments are run as a path query.
+func run(args []string, stdout, stderr io.Writer) int {
+	env := &environment{stdout: stdout, stderr: stderr,
+		rate:        20,
+		burst:       10,
+		cacheDir:    defaultCacheDir(),
+		cacheTTL:    7 * 24 * time.Hour,
+		concurrency: 16}
 
-	paths := sourceNode.PathsTo(targetNode, true)
-	if len(paths) == 0 {
-		fmt.Printf("\nCould not find a connection between %v and %v\n", sourceNode, targetNode)
-	} else {
-		fmt.Printf("\n!!!!!!!!!!!!!!! Degrees of Separation: %v\n", len(paths[0]))
-		for i, node := range paths[0] {
-			fmt.Printf("%d. %v\n", i, node)
+	name, commandArgs := "path", args
+	globals := flag.NewFlagSet("degrees", flag.ContinueOnError)
+	globals.SetOutput(ioutil.Discard)
+	env.registerFlags(globals)
+	if err := globals.Parse(args); err == nil && globals.NArg() > 0 {
+		if _, known := commands[globals.Arg(0)]; known {
+			name, commandArgs = globals.Arg(0), globals.Args()[1:]
 		}
 	}
+	return commands[name](env, commandArgs)
+}
+
+// registerFlags registers the global flags with a command's FlagSet, defaulting to their current values.
+func (env *environment) registerFlags(flags *flag.FlagSet) {
+	flags.StringVar(&env.source, "source", env.source, "Where to read Moviebuff entities from: http, or dir to read them from -data-dir.")
+	flags.StringVar(&env.dataDir, "data-dir", env.dataDir, "Read Moviebuff entities from this directory of <slug> JSON files instead of fetching them.")
+	flags.Float64Var(&env.rate, "rate", env.rate, "Maximum average number of requests per second to make to Moviebuff. Negative values disable rate limiting.")
+	flags.IntVar(&env.burst, "burst", env.burst, "Maximum number of requests to make to Moviebuff in a burst.")
+	flags.StringVar(&env.cacheDir, "cache-dir", env.cacheDir, "Directory to cache Moviebuff entities in.")
+	flags.DurationVar(&env.cacheTTL, "cache-ttl", env.cacheTTL, "How long cached Moviebuff entities stay fresh.")
+	flags.BoolVar(&env.noCache, "no-cache", env.noCache, "Always fetch Moviebuff entities instead of using the cache.")
+	flags.IntVar(&env.concurrency, "concurrency", env.concurrency, "Maximum number of Moviebuff entities to fetch concurrently.")
+	flags.BoolVar(&env.verbose, "v", env.verbose, "Log progress to stderr.")
+	flags.StringVar(&env.roleWeights, "role-weights", env.roleWeights,
+		"Weights of credits by role for the strongest searches, e.g. Producer=2,Cameo=10. Lower is stronger. Unlisted roles use default weights.")
+}
+
+// newFlagSet creates a FlagSet for a command, with the global flags registered on it.
+func (env *environment) newFlagSet(name string, commandUsage string) *flag.FlagSet {
+	flags := flag.NewFlagSet("degrees "+name, flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		fmt.Fprint(env.stderr, usage)
+		fmt.Fprintf(env.stderr, "\nUsage: degrees %v\n\nFlags:\n", commandUsage)
+		flags.PrintDefaults()
+	}
+	env.registerFlags(flags)
+	return flags
+}
+
+// parse parses a command's arguments, and sets up the data source selected by the global flags.
+// Returns the exit code to stop with if the arguments are invalid, or -1 to carry on.
+func (env *environment) parse(flags *flag.FlagSet, args []string) int {
+	err := flags.Parse(args)
+	if err == flag.ErrHelp {
+		return exitConnected
+	}
+	if err == nil {
+		err = env.setup()
+	}
+	if err != nil {
+		return env.usageError(err)
+	}
+	return -1
+}
+
+// usageError reports invalid usage, and returns the exit code for it.
+func (env *environment) usageError(err error) int {
+	fmt.Fprintf(env.stderr, "degrees: %v\nRun 'degrees -h' for usage.\n", err)
+	return exitUsage
+}
+
+// setup validates the global flags, and builds the data source they select.
+func (env *environment) setup() error {
+	if env.concurrency < 1 {
+		return errors.New("-concurrency must be at least 1")
+	}
+	if env.rate == 0 {
+		return errors.New("-rate must not be 0: use a negative rate to disable rate limiting")
+	}
+	if env.burst < 1 {
+		return errors.New("-burst must be at least 1")
+	}
+	if env.source == "" {
+		env.source = sourceHTTP
+		if env.dataDir != "" {
+			env.source = sourceDir
+		}
+	}
+
+	weights, err := parseRoleWeights(env.roleWeights)
+	if err != nil {
+		return err
+	}
+	env.weigh = moviebuff.WeighRoles(weights)
+	// The lightest default weight is 1
+	env.minWeight = 1
+	for _, weight := range weights {
+		env.minWeight = math.Min(env.minWeight, weight)
+	}
+
+	env.client = moviebuff.NewClient(moviebuff.Config{RequestsPerSecond: env.rate, Burst: env.burst, Cache: env.cache()})
+	switch env.source {
+	case sourceHTTP:
+		env.fetcher = moviebuff.NewFetcher(env.client, env.weigh)
+	case sourceDir:
+		if env.dataDir == "" {
+			return errors.New("-source=dir requires -data-dir")
+		}
+		env.fetcher = moviebuff.NewFetcher(moviebuff.NewDirSource(env.dataDir), env.weigh)
+	default:
+		return fmt.Errorf("unknown source %q", env.source)
+	}
+	return nil
+}
+
+// parseRoleWeights parses a comma separated list of role=weight pairs.
+func parseRoleWeights(list string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid role weight %q: expected role=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid role weight %q: weights must be positive numbers", pair)
+		}
+		weights[strings.TrimSpace(parts[0])] = weight
+	}
+	return weights, nil
+}
+
+// cache returns the DiskCache selected by the global flags, or nil if caching is disabled.
+func (env *environment) cache() *moviebuff.DiskCache {
+	if env.noCache || env.cacheDir == "" {
+		return nil
+	}
+	return moviebuff.NewDiskCache(env.cacheDir, env.cacheTTL)
+}
+
+// newNodeGroup creates a NodeGroup for a single query, searching up to maxDepth hops.
+func (env *environment) newNodeGroup(maxDepth int) *graph.NodeGroup {
+	return graph.NewNodeGroup(maxDepth, env.concurrency)
+}
+
+// nodes creates Nodes for the given slugs in a NodeGroup, loading from the selected data source.
+func (env *environment) nodes(group *graph.NodeGroup, slugs []string) []*graph.Node {
+	nodes := []*graph.Node{}
+	for _, slug := range slugs {
+		nodes = append(nodes, graph.NewNode(slug, env.fetcher, group))
+	}
+	return nodes
+}
+
+func (env *environment) logf(format string, args ...interface{}) {
+	if env.verbose {
+		fmt.Fprintf(env.stderr, format+"\n", args...)
+	}
+}
+
+// validateSlugs returns an error if any of the slugs doesn't look like a Moviebuff URL.
+func validateSlugs(slugs []string) error {
+	for _, slug := range slugs {
+		if !slugPattern.MatchString(slug) {
+			return fmt.Errorf("invalid Moviebuff URL %q: expected something like amitabh-bachchan", slug)
+		}
+	}
+	return nil
+}
+
+// listFlag is a flag that can be repeated, or given a comma separated list, to collect several values.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return fmt.Sprint(*l)
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, slug := range regexp.MustCompile(`\s*,\s*`).Split(value, -1) {
+		if slug != "" {
+			*l = append(*l, slug)
+		}
+	}
+	return nil
+}
+
+// sortedNodes returns the given Nodes sorted by ID.
+func sortedNodes(nodes map[string]*graph.Node) []*graph.Node {
+	result := []*graph.Node{}
+	for _, node := range nodes {
+		result = append(result, node)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// defaultCacheDir returns the per-user cache directory for degrees, or an empty string if there isn't one.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "degrees")
 }
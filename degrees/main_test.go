@@ -1 +1,331 @@
 package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeDump writes a tiny Moviebuff dump to a temporary directory:
+// amitabh-bachchan -- the-great-gatsby -- leonardo-dicaprio, and a-recluse who has no movies.
+func writeDump(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "degrees-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities := map[string]string{
+		"amitabh-bachchan": `{"url":"amitabh-bachchan","type":"Person","name":"Amitabh Bachchan",
+		"movies":[{"url":"the-great-gatsby","name":"The Great Gatsby","role":"Supporting Actor"}]}`,
+		"the-great-gatsby": `{"url":"the-great-gatsby","type":"Movie","name":"The Great Gatsby",
+		"cast":[{"url":"amitabh-bachchan","name":"Amitabh Bachchan","role":"Supporting Actor"},
+		{"url":"leonardo-dicaprio","name":"Leonardo DiCaprio","role":"Actor"}]}`,
+		"leonardo-dicaprio": `{"url":"leonardo-dicaprio","type":"Person","name":"Leonardo DiCaprio",
+		"movies":[{"url":"the-great-gatsby","name":"The Great Gatsby","role":"Actor"}]}`,
+		"a-recluse": `{"url":"a-recluse","type":"Person","name":"A Recluse"}`,
+	}
+	for slug, json := range entities {
+		ioutil.WriteFile(filepath.Join(dir, slug), []byte(json), 0644)
+	}
+	return dir
+}
+
+func runDegrees(args ...string) (int, string, string) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(args, stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestDegreesFindsConnections(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Equal(t, `
+Degrees of Separation: 1
+
+1. Movie: The Great Gatsby
+Supporting Actor: Amitabh Bachchan
+Actor: Leonardo DiCaprio
+`, stdout)
+}
+
+func TestDegreesExitCodes(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+
+	code, _, _ := runDegrees("-data-dir", dir, "amitabh-bachchan", "a-recluse")
+	assert.Equal(t, exitNotConnected, code)
+
+	code, _, stderr := runDegrees("-data-dir", dir, "amitabh-bachchan", "nobody-at-all")
+	assert.Equal(t, exitUnknownPerson, code)
+	assert.Contains(t, stderr, "unknown person nobody-at-all")
+
+	code, _, stderr = runDegrees("-data-dir", dir, "amitabh-bachchan", "the-great-gatsby")
+	assert.Equal(t, exitUnknownPerson, code)
+	assert.Contains(t, stderr, "not a person")
+}
+
+func TestDegreesValidatesUsage(t *testing.T) {
+	code, _, stderr := runDegrees()
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "expected exactly two people")
+
+	code, _, stderr = runDegrees("Amitabh Bachchan", "robert-de-niro")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "invalid Moviebuff URL")
+
+	code, _, _ = runDegrees("-max-depth", "0", "amitabh-bachchan", "robert-de-niro")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runDegrees("-format", "xml", "amitabh-bachchan", "robert-de-niro")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = runDegrees("-source", "dir", "amitabh-bachchan", "robert-de-niro")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, stderr = runDegrees("-rate", "0", "amitabh-bachchan", "robert-de-niro")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "use a negative rate to disable rate limiting")
+
+	code, _, stderr = runDegrees("-burst", "0", "amitabh-bachchan", "robert-de-niro")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "-burst must be at least 1")
+
+	code, _, stderr = runDegrees("-h")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stderr, "Usage: degrees")
+}
+
+func TestDegreesDispatchesCommands(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "path", "-format", "ndjson", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, `"connected":true`)
+
+	code, stdout, _ = runDegrees("path", "-data-dir", dir, "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "Degrees of Separation: 1")
+
+	code, stdout, _ = runDegrees("-cache-dir", dir, "stats")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "Entities: 0 (0 people, 0 movies)")
+
+	code, _, stderr := runDegrees("-no-cache", "stats")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "stats needs a cache")
+
+	code, _, stderr = runDegrees("crawl", "-h")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stderr, "-seed")
+	assert.Contains(t, stderr, "-cache-dir")
+}
+
+// writeCameo adds a second movie connecting amitabh-bachchan and leonardo-dicaprio to a dump written by writeDump.
+func writeCameo(dir string) {
+	entities := map[string]string{
+		"amitabh-bachchan": `{"url":"amitabh-bachchan","type":"Person","name":"Amitabh Bachchan",
+		"movies":[{"url":"the-great-gatsby","name":"The Great Gatsby","role":"Supporting Actor"},
+		{"url":"a-cameo","name":"A Cameo","role":"Himself"}]}`,
+		"leonardo-dicaprio": `{"url":"leonardo-dicaprio","type":"Person","name":"Leonardo DiCaprio",
+		"movies":[{"url":"the-great-gatsby","name":"The Great Gatsby","role":"Actor"},
+		{"url":"a-cameo","name":"A Cameo","role":"Himself"}]}`,
+		"a-cameo": `{"url":"a-cameo","type":"Movie","name":"A Cameo",
+		"cast":[{"url":"amitabh-bachchan","name":"Amitabh Bachchan","role":"Himself"},
+		{"url":"leonardo-dicaprio","name":"Leonardo DiCaprio","role":"Himself"}]}`,
+	}
+	for slug, json := range entities {
+		ioutil.WriteFile(filepath.Join(dir, slug), []byte(json), 0644)
+	}
+}
+
+func TestDegreesListsEveryShortestChain(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+	writeCameo(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "path", "-all", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Equal(t, `
+Degrees of Separation: 1
+
+There are 2 different 1-degree chains
+
+Chain 1:
+
+1. Movie: A Cameo
+Himself: Amitabh Bachchan
+Himself: Leonardo DiCaprio
+
+Chain 2:
+
+1. Movie: The Great Gatsby
+Supporting Actor: Amitabh Bachchan
+Actor: Leonardo DiCaprio
+`, stdout)
+
+	code, stdout, _ = runDegrees("-data-dir", dir, "path", "-all", "-max-chains", "1", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "There are 2 different 1-degree chains. Showing the first 1\n")
+	assert.NotContains(t, stdout, "Chain 2:")
+}
+
+func TestDegreesListsTheKShortestChains(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+	writeCameo(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "path", "-k", "1", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "The shortest chain\n\nChain 1, 1 degree:\n")
+
+	code, stdout, _ = runDegrees("-data-dir", dir, "path", "-k", "5", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "Found only 2 chains within the maximum degrees of separation\n")
+	assert.Contains(t, stdout, "Chain 2, 1 degree:")
+
+	code, _, stderr := runDegrees("-data-dir", dir, "path", "-k", "5", "-all", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "can't be used together")
+}
+
+func TestDegreesFindsTheStrongestChain(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+	writeCameo(dir)
+
+	for _, search := range []string{"strongest", "astar"} {
+		code, stdout, _ := runDegrees("-data-dir", dir, "-role-weights", "Himself=0.5", "path", "-search", search, "amitabh-bachchan", "leonardo-dicaprio")
+		assert.Equal(t, exitConnected, code)
+		assert.Contains(t, stdout, "Weight: 1 (lower is stronger)\n\n1. Movie: A Cameo\n")
+
+		code, stdout, _ = runDegrees("-data-dir", dir, "-role-weights", "Himself=5", "path", "-search", search, "amitabh-bachchan", "leonardo-dicaprio")
+		assert.Equal(t, exitConnected, code)
+		assert.Contains(t, stdout, "Weight: 4 (lower is stronger)\n\n1. Movie: The Great Gatsby\n")
+	}
+
+	code, _, stderr := runDegrees("-data-dir", dir, "path", "-search", "strongest", "-k", "2", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "can't be used with all or k")
+
+	code, _, stderr = runDegrees("-data-dir", dir, "-role-weights", "Cameo=-1", "path", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "weights must be positive numbers")
+}
+
+func TestDegreesOnlyConnectsThroughAllowedRoles(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+	writeCameo(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "path", "-allow-roles", "Actor,Supporting Actor", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "1. Movie: The Great Gatsby\n")
+
+	code, stdout, _ = runDegrees("-data-dir", dir, "path", "-deny-roles", "actor", "-deny-roles", "supporting actor", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "1. Movie: A Cameo\n")
+
+	code, _, _ = runDegrees("-data-dir", dir, "path", "-allow-roles", "Director", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitNotConnected, code)
+}
+
+func TestDegreesAvoidsAndGoesViaEntities(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+	writeCameo(dir)
+
+	code, stdout, _ := runDegrees("-data-dir", dir, "path", "-avoid", "the-great-gatsby", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitConnected, code)
+	assert.Contains(t, stdout, "1. Movie: A Cameo\n")
+
+	code, _, _ = runDegrees("-data-dir", dir, "path", "-avoid", "the-great-gatsby,a-cameo", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitNotConnected, code)
+
+	for slug, movie := range map[string]string{"the-great-gatsby": "The Great Gatsby", "a-cameo": "A Cameo"} {
+		code, stdout, _ = runDegrees("-data-dir", dir, "path", "-via", slug, "amitabh-bachchan", "leonardo-dicaprio")
+		assert.Equal(t, exitConnected, code)
+		assert.Contains(t, stdout, "Degrees of Separation: 1\n")
+		assert.Contains(t, stdout, "1. Movie: "+movie+"\n")
+	}
+
+	// Reaching a-cameo after the-great-gatsby would have to go back through one of the people
+	code, _, _ = runDegrees("-data-dir", dir, "path", "-via", "the-great-gatsby,a-cameo", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitNotConnected, code)
+
+	code, _, stderr := runDegrees("-data-dir", dir, "path", "-via", "nothing-at-all", "amitabh-bachchan", "leonardo-dicaprio")
+	assert.Equal(t, exitUnknownPerson, code)
+	assert.Contains(t, stderr, "unknown waypoint nothing-at-all")
+}
+
+func TestDegreesValidatesAvoidAndVia(t *testing.T) {
+	for _, args := range [][]string{
+		{"-avoid", "Amitabh Bachchan"},
+		{"-avoid", "amitabh-bachchan"},
+		{"-avoid", "sholay", "-via", "sholay"},
+		{"-via", "sholay", "-all"},
+		{"-via", "sholay", "-k", "3"},
+		{"-via", "sholay", "-search", "strongest"},
+	} {
+		code, _, _ := runDegrees(append(append([]string{"path"}, args...), "amitabh-bachchan", "robert-de-niro")...)
+		if code != exitUsage {
+			t.Errorf("Expected %v to be a usage error, got exit code %v", args, code)
+		}
+	}
+}
+
+func TestDegreesShowsTheChainsFoundBeforeTimingOut(t *testing.T) {
+	/*
+	   person-a -- movie-one -- person-l
+	      |                        |
+	   movie-two -- person-x -- movie-three, where person-x never loads
+	*/
+	dir, err := ioutil.TempDir("", "degrees-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	entities := map[string]string{
+		"person-a": `{"url":"person-a","type":"Person","name":"Person A",
+		"movies":[{"url":"movie-one","name":"Movie One","role":"Actor"},{"url":"movie-two","name":"Movie Two","role":"Actor"}]}`,
+		"movie-one": `{"url":"movie-one","type":"Movie","name":"Movie One",
+		"cast":[{"url":"person-a","name":"Person A","role":"Actor"},{"url":"person-l","name":"Person L","role":"Actor"}]}`,
+		"movie-two": `{"url":"movie-two","type":"Movie","name":"Movie Two",
+		"cast":[{"url":"person-a","name":"Person A","role":"Actor"},{"url":"person-x","name":"Person X","role":"Actor"}]}`,
+		"person-l": `{"url":"person-l","type":"Person","name":"Person L",
+		"movies":[{"url":"movie-one","name":"Movie One","role":"Actor"},{"url":"movie-three","name":"Movie Three","role":"Actor"}]}`,
+	}
+	for slug, json := range entities {
+		ioutil.WriteFile(filepath.Join(dir, slug), []byte(json), 0644)
+	}
+
+	env := &environment{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, dataDir: dir, concurrency: 4, rate: 20, burst: 10, noCache: true}
+	assert.Nil(t, env.setup())
+	fetch := env.fetcher
+	env.fetcher = func(ctx context.Context, n *graph.Node) error {
+		if n.ID == "person-x" {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return fetch(ctx, n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r, err := env.connect(ctx, query{from: "person-a", to: "person-l", maxDepth: 6, k: 2, search: searchShortest})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, exitTimeout, err.(*queryError).code)
+	}
+	assert.True(t, r.Connected)
+	assert.Equal(t, 1, r.Chains)
+	assert.Equal(t, 2, r.K)
+	assert.Equal(t, "movie-one", r.Hops[0].Movie.ID)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/CodeMangler/degrees-of-separation/moviebuff"
+	"sync/atomic"
+	"time"
+)
+
+// errTimeout is returned when a query times out before it can complete.
+var errTimeout = errors.New("timed out")
+
+// queryError is a failed query, along with the exit code that describes the failure.
+type queryError struct {
+	code int
+	err  error
+}
+
+func (e *queryError) Error() string {
+	return e.err.Error()
+}
+
+// Search strategies
+const (
+	searchShortest  = "shortest"
+	searchStrongest = "strongest"
+	searchAStar     = "astar"
+)
+
+// query describes a path query between two people.
+type query struct {
+	from      string
+	to        string
+	maxDepth  int
+	all       bool
+	maxChains int
+	k         int
+	search    string
+	allow     []string
+	deny      []string
+	avoid     []string
+	via       []string
+}
+
+// validate returns an error if the query's options can't be used together.
+// Both the path command and the HTTP service validate queries this way, so that they accept the same queries.
+func (q query) validate() error {
+	if err := q.checkSearch(); err != nil {
+		return err
+	}
+	return q.checkWaypoints()
+}
+
+// checkSearch returns an error if the query's search can't be used with the rest of it.
+func (q query) checkSearch() error {
+	if q.all && q.k > 0 {
+		return errors.New("all and k can't be used together")
+	}
+	switch q.search {
+	case searchShortest:
+	case searchStrongest, searchAStar:
+		if q.all || q.k > 0 {
+			return fmt.Errorf("search %v can't be used with all or k", q.search)
+		}
+	default:
+		return fmt.Errorf("unknown search %q", q.search)
+	}
+	return nil
+}
+
+// checkWaypoints returns an error if the entities a query avoids or goes through can't be used with the rest of it.
+func (q query) checkWaypoints() error {
+	if err := validateSlugs(append(append([]string{}, q.avoid...), q.via...)); err != nil {
+		return err
+	}
+	for _, slug := range q.avoid {
+		if slug == q.from || slug == q.to {
+			return fmt.Errorf("can't avoid %v, who is being connected", slug)
+		}
+		for _, waypoint := range q.via {
+			if slug == waypoint {
+				return fmt.Errorf("can't both avoid and go via %v", slug)
+			}
+		}
+	}
+	if len(q.via) > 0 && (q.all || q.k > 0 || q.search != searchShortest) {
+		return errors.New("via can only be used to find a single shortest chain, not with all, k or a strongest search")
+	}
+	return nil
+}
+
+// runPath finds the smallest degree of separation between two people, and prints how they are connected.
+func runPath(env *environment, args []string) int {
+	flags := env.newFlagSet("path", "path [flags] <person> <person>")
+	maxDepth := flags.Int("max-depth", 6, "Maximum degrees of separation to search.")
+	timeout := flags.Duration("timeout", 0, "Give up searching after this long, e.g. 30s or 2m. Searches until done if 0.")
+	format := flags.String("format", formatText, "Output format: text, json or ndjson.")
+	all := flags.Bool("all", false, "List every chain with the smallest degree of separation, instead of just one.")
+	maxChains := flags.Int("max-chains", 10, "Maximum number of chains to list with -all. Lists all of them if 0.")
+	k := flags.Int("k", 0, "List the k shortest chains, including longer ones, instead of just one.")
+	strategy := flags.String("search", searchShortest, "Search for the shortest chain, or the strongest chain by the -role-weights of its credits: "+
+		"shortest, strongest, or astar to search for the strongest chain with A*.")
+	allow, deny := &listFlag{}, &listFlag{}
+	flags.Var(allow, "allow-roles", "Only connect people through credits for these roles, e.g. Actor,Actress. Repeat, or separate with commas.")
+	flags.Var(deny, "deny-roles", "Never connect people through credits for these roles, e.g. Director. Repeat, or separate with commas.")
+	avoid, via := &listFlag{}, &listFlag{}
+	flags.Var(avoid, "avoid", "Never connect people through these people or movies, e.g. amitabh-bachchan. Repeat, or separate with commas.")
+	flags.Var(via, "via", "Connect people through these people or movies, in order, e.g. sholay. Repeat, or separate with commas.")
+	if code := env.parse(flags, args); code >= 0 {
+		return code
+	}
+
+	people := flags.Args()
+	if len(people) != 2 {
+		return env.usageError(fmt.Errorf("expected exactly two people, got %v", len(people)))
+	}
+	if err := validateSlugs(people); err != nil {
+		return env.usageError(err)
+	}
+	if *maxDepth < 1 {
+		return env.usageError(errors.New("-max-depth must be at least 1"))
+	}
+	if *maxChains < 0 {
+		return env.usageError(errors.New("-max-chains must not be negative"))
+	}
+	if *k < 0 {
+		return env.usageError(errors.New("-k must not be negative"))
+	}
+	switch *format {
+	case formatText, formatJSON, formatNDJSON:
+	default:
+		return env.usageError(fmt.Errorf("unknown output format %q", *format))
+	}
+
+	q := query{from: people[0], to: people[1], maxDepth: *maxDepth, all: *all, maxChains: *maxChains, k: *k, search: *strategy,
+		allow: *allow, deny: *deny, avoid: *avoid, via: *via}
+	if err := q.validate(); err != nil {
+		return env.usageError(err)
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	r, err := env.connect(ctx, q)
+	if err != nil && r.Source.ID == "" {
+		fmt.Fprintf(env.stderr, "degrees: %v\n", err)
+		return err.(*queryError).code
+	}
+	if err := render(env.stdout, *format, r); err != nil {
+		fmt.Fprintf(env.stderr, "degrees: %v\n", err)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintf(env.stderr, "degrees: %v\n", err)
+		return err.(*queryError).code
+	}
+	if !r.Connected {
+		return exitNotConnected
+	}
+	return exitConnected
+}
+
+// connect searches for the smallest degree of separation between two people, up to maxDepth degrees apart.
+// With all set, every chain with the smallest degree of separation is counted, and up to maxChains of them are listed.
+// With k set, the k shortest chains are listed instead, including longer ones.
+// The strongest and astar searches find the chain with the lowest total weight instead, weighing credits by role.
+// Only credits for roles in allow, if any, and not in deny are traversed, and chains never go through anyone or
+// anything in avoid. With via set, the shortest chain going through each of its entities in order is found instead.
+// Returns a *queryError if either person or any entity in via couldn't be loaded, if the search timed out, or if it may have missed a
+// connection because some entities couldn't be fetched. The result is returned along with the last of these, and with
+// a timeout if the search found some chains before it timed out.
+func (env *environment) connect(ctx context.Context, q query) (result, error) {
+	start := time.Now()
+	// The HTTP service shares one client between concurrent queries, so count this query's requests separately
+	var requests int64
+	ctx = moviebuff.WithRequestCounter(ctx, &requests)
+	// Each degree of separation is a hop from a person to a movie, and another from the movie to the next person
+	nodeGroup := env.newNodeGroup(2 * q.maxDepth)
+	sourceNode := graph.NewNode(q.from, env.fetcher, nodeGroup)
+	targetNode := graph.NewNode(q.to, env.fetcher, nodeGroup)
+
+	for _, person := range []*graph.Node{sourceNode, targetNode} {
+		env.logf("Loading %v", person)
+		if err := loadPerson(ctx, nodeGroup.Loader(), person); err != nil {
+			return result{}, err
+		}
+	}
+	waypoints := []*graph.Node{}
+	for _, slug := range q.via {
+		waypoint := graph.NewNode(slug, env.fetcher, nodeGroup)
+		env.logf("Loading %v", waypoint)
+		if err := loadEntity(ctx, nodeGroup.Loader(), waypoint, "waypoint"); err != nil {
+			return result{}, err
+		}
+		waypoints = append(waypoints, waypoint)
+	}
+
+	env.logf("Searching for a connection between %v and %v within %v degrees", sourceNode, targetNode, q.maxDepth)
+	search := graph.NewSearch(ctx, nodeGroup)
+	defer search.Cancel()
+	if len(q.allow) > 0 || len(q.deny) > 0 {
+		search.SetFilter(moviebuff.NewRoleFilter(q.allow, q.deny))
+	}
+	for _, slug := range q.avoid {
+		search.Avoid(graph.NewNode(slug, env.fetcher, nodeGroup))
+	}
+	var paths []graph.Path
+	var err error
+	switch {
+	case q.all:
+		paths, err = search.AllShortestPaths(sourceNode, targetNode, q.maxChains)
+	case q.k > 0:
+		paths, err = search.KShortestPaths(sourceNode, targetNode, q.k)
+	case len(waypoints) > 0:
+		var via graph.Path
+		if via, err = search.PathVia(sourceNode, targetNode, waypoints...); via != nil {
+			paths = []graph.Path{via}
+		}
+	case q.search == searchStrongest || q.search == searchAStar:
+		var heuristic graph.Heuristic
+		if q.search == searchAStar {
+			heuristic = moviebuff.NewHeuristic(env.minWeight)
+		}
+		var strongest graph.Path
+		if strongest, _, err = search.WeightedPath(sourceNode, targetNode, heuristic); strongest != nil {
+			paths = []graph.Path{strongest}
+		}
+	default:
+		var shortest graph.Path
+		if shortest, err = search.BidirectionalPath(sourceNode, targetNode); shortest != nil {
+			paths = []graph.Path{shortest}
+		}
+	}
+	timedOut := err == context.DeadlineExceeded
+	if timedOut && len(paths) == 0 {
+		return result{}, &queryError{exitTimeout, fmt.Errorf("%v without finding a connection between %v and %v", errTimeout, sourceNode, targetNode)}
+	}
+
+	var path graph.Path
+	if len(paths) > 0 {
+		path = paths[0]
+	}
+	r := newResult(sourceNode, targetNode, path, time.Since(start))
+	if q.search == searchStrongest || q.search == searchAStar {
+		r.Weight = path.Weight()
+	}
+	switch {
+	case q.all && r.Connected:
+		r.addChains(paths, search.Stats().PathsFound)
+	case q.k > 0 && r.Connected:
+		r.addChains(paths, len(paths))
+		r.K = q.k
+	}
+	r.Stats.NodesLoaded = nodeGroup.Loader().Loaded()
+	r.Stats.HTTPRequests = int(atomic.LoadInt64(&requests))
+	env.logf("Loaded %v entities with %v HTTP requests in %vms", r.Stats.NodesLoaded, r.Stats.HTTPRequests, r.Stats.ElapsedMs)
+
+	if timedOut {
+		return r, &queryError{exitTimeout, fmt.Errorf("%v after finding %v of the chains between %v and %v", errTimeout, len(paths), sourceNode, targetNode)}
+	}
+	if failed := nodeGroup.Loader().Failed(); !r.Connected && failed > 0 {
+		return r, &queryError{exitNetworkFailure, fmt.Errorf("%v entities could not be fetched, so a connection may have been missed", failed)}
+	}
+	return r, nil
+}
+
+// loadPerson loads one of the people being connected up front, so that unknown people are reported as such,
+// instead of as being unconnected. Returns a *queryError if the person couldn't be loaded.
+func loadPerson(ctx context.Context, loader *graph.Loader, person *graph.Node) error {
+	if err := loadEntity(ctx, loader, person, "person"); err != nil {
+		return err
+	}
+	if entity, _ := person.Data().(*moviebuff.Entity); entity != nil && entity.Type != "Person" {
+		return &queryError{exitUnknownPerson, fmt.Errorf("%v is a %v, not a person", person, entity.Type)}
+	}
+	return nil
+}
+
+// loadEntity loads an entity a query needs up front, and describes it as the given kind of entity if it's unknown.
+// Returns a *queryError if the entity couldn't be loaded.
+func loadEntity(ctx context.Context, loader *graph.Loader, node *graph.Node, kind string) error {
+	err := loader.Load(ctx, node)
+	switch {
+	case err == context.DeadlineExceeded:
+		return &queryError{exitTimeout, fmt.Errorf("%v loading %v", errTimeout, node)}
+	case graph.IsPermanent(err):
+		return &queryError{exitUnknownPerson, fmt.Errorf("unknown %v %v: %v", kind, node, err)}
+	case err != nil:
+		return &queryError{exitNetworkFailure, fmt.Errorf("could not fetch %v: %v", node, err)}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/CodeMangler/degrees-of-separation/moviebuff"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	formatText   = "text"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// result is the outcome of a degrees query, in a stable schema for machine readable output.
+type result struct {
+	Source    credit  `json:"source"`
+	Target    credit  `json:"target"`
+	Connected bool    `json:"connected"`
+	Degrees   int     `json:"degrees"`
+	Hops      []hop   `json:"hops"`
+	Chains    int     `json:"chains,omitempty"`
+	K         int     `json:"k,omitempty"`
+	Weight    float64 `json:"weight,omitempty"`
+	ChainHops [][]hop `json:"chainHops,omitempty"`
+	Stats     stats   `json:"stats"`
+}
+
+// hop is a single Movie along a path, and the two people it connects.
+type hop struct {
+	Movie credit `json:"movie"`
+	From  credit `json:"from"`
+	To    credit `json:"to"`
+}
+
+// credit identifies an entity along a path, and the role a person had in the hop's movie.
+type credit struct {
+	ID   string `json:"slug"`
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+// stats describes the work done to answer a query.
+type stats struct {
+	NodesLoaded  int   `json:"nodesLoaded"`
+	HTTPRequests int   `json:"httpRequests"`
+	ElapsedMs    int64 `json:"elapsedMs"`
+}
+
+// newResult describes the connection between source and target along the given path.
+// A nil path means the two aren't connected.
+func newResult(source, target *graph.Node, path graph.Path, elapsed time.Duration) result {
+	r := result{Source: credit{ID: source.ID, Name: nameOf(source, "")},
+		Target: credit{ID: target.ID, Name: nameOf(target, "")},
+		Hops:   []hop{},
+		Stats:  stats{ElapsedMs: int64(elapsed / time.Millisecond)}}
+	if path != nil {
+		r.Connected = true
+		r.Hops = hops(path)
+		r.Degrees = len(r.Hops)
+	}
+	return r
+}
+
+// addChains lists several alternative minimum-length paths between source and target, out of total such paths.
+func (r *result) addChains(paths []graph.Path, total int) {
+	r.Chains = total
+	for _, path := range paths {
+		r.ChainHops = append(r.ChainHops, hops(path))
+	}
+}
+
+// hops breaks a person–movie–person path down into the movies traversed, along with who connects through each of them.
+func hops(path graph.Path) []hop {
+	result := []hop{}
+	for i := 1; i+1 < len(path); i++ {
+		in, isCredit := path.Edge(i - 1).Data.(moviebuff.Credit)
+		if !isCredit || in.Movie != path[i].ID {
+			continue
+		}
+		out, _ := path.Edge(i).Data.(moviebuff.Credit)
+		result = append(result, hop{
+			Movie: credit{ID: path[i].ID, Name: nameOf(path[i], in.MovieName)},
+			From:  credit{ID: path[i-1].ID, Name: nameOf(path[i-1], in.PersonName), Role: strings.Join(in.Roles, ", ")},
+			To:    credit{ID: path[i+1].ID, Name: nameOf(path[i+1], out.PersonName), Role: strings.Join(out.Roles, ", ")},
+		})
+	}
+	return result
+}
+
+// nameOf returns the name of the entity a Node was loaded with, falling back to the given name, and then to the Node ID.
+func nameOf(node *graph.Node, fallback string) string {
+	if entity, loaded := node.Data().(*moviebuff.Entity); loaded && entity.Name != "" {
+		return entity.Name
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return node.ID
+}
+
+// render writes the result in the given format: text, json or ndjson.
+func render(w io.Writer, format string, r result) error {
+	switch format {
+	case formatText:
+		renderText(w, r)
+		return nil
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case formatNDJSON:
+		return json.NewEncoder(w).Encode(r)
+	}
+	return fmt.Errorf("unknown output format: %v", format)
+}
+
+// renderText writes the degrees of separation, followed by a numbered list of the movies connecting source and target.
+func renderText(w io.Writer, r result) {
+	if !r.Connected {
+		fmt.Fprintf(w, "\nCould not find a connection between %v and %v\n", r.Source.ID, r.Target.ID)
+		return
+	}
+	fmt.Fprintf(w, "\nDegrees of Separation: %v\n", r.Degrees)
+	if r.Weight > 0 {
+		fmt.Fprintf(w, "Weight: %v (lower is stronger)\n", r.Weight)
+	}
+	if r.Chains == 0 {
+		renderHops(w, r.Hops)
+		return
+	}
+	if r.K > 0 {
+		renderRankedChains(w, r)
+		return
+	}
+
+	switch {
+	case r.Chains == 1:
+		fmt.Fprintf(w, "\nThere is only one %v-degree chain\n", r.Degrees)
+	case len(r.ChainHops) == r.Chains:
+		fmt.Fprintf(w, "\nThere are %v different %v-degree chains\n", r.Chains, r.Degrees)
+	default:
+		fmt.Fprintf(w, "\nThere are %v different %v-degree chains. Showing the first %v\n", r.Chains, r.Degrees, len(r.ChainHops))
+	}
+	for i, chain := range r.ChainHops {
+		fmt.Fprintf(w, "\nChain %d:\n", i+1)
+		renderHops(w, chain)
+	}
+}
+
+// renderRankedChains writes the k shortest chains connecting source and target, along with the degrees of each.
+func renderRankedChains(w io.Writer, r result) {
+	switch {
+	case r.Chains < r.K:
+		fmt.Fprintf(w, "\nFound only %v within the maximum degrees of separation\n", pluralize(r.Chains, "chain"))
+	case r.Chains == 1:
+		fmt.Fprintf(w, "\nThe shortest chain\n")
+	default:
+		fmt.Fprintf(w, "\nThe %v shortest chains\n", r.Chains)
+	}
+	for i, chain := range r.ChainHops {
+		fmt.Fprintf(w, "\nChain %d, %v:\n", i+1, pluralize(len(chain), "degree"))
+		renderHops(w, chain)
+	}
+}
+
+// pluralize returns a count of things, with the thing pluralized unless the count is 1.
+func pluralize(count int, thing string) string {
+	if count == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%v %vs", count, thing)
+}
+
+// renderHops writes a numbered list of the movies along a path.
+func renderHops(w io.Writer, hops []hop) {
+	for i, h := range hops {
+		fmt.Fprintf(w, "\n%d. Movie: %v\n", i+1, h.Movie.Name)
+		fmt.Fprintf(w, "%v: %v\n", roleOrDefault(h.From.Role), h.From.Name)
+		fmt.Fprintf(w, "%v: %v\n", roleOrDefault(h.To.Role), h.To.Name)
+	}
+}
+
+func roleOrDefault(role string) string {
+	if role == "" {
+		return "Unknown Role"
+	}
+	return role
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/CodeMangler/degrees-of-separation/moviebuff"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func gatsbyToScorsese() (graph.Path, *graph.Node, *graph.Node) {
+	group := graph.NewNodeGroup()
+	amitabh := graph.NewNode("amitabh-bachchan", nil, group)
+	gatsby := graph.NewNode("the-great-gatsby", nil, group)
+	leo := graph.NewNode("leonardo-dicaprio", nil, group)
+	wolf := graph.NewNode("the-wolf-of-wall-street", nil, group)
+	scorsese := graph.NewNode("martin-scorsese", nil, group)
+
+	amitabh.SetData(&moviebuff.Entity{ID: "amitabh-bachchan", Name: "Amitabh Bachchan", Type: "Person"})
+	gatsby.SetData(&moviebuff.Entity{ID: "the-great-gatsby", Name: "The Great Gatsby", Type: "Movie"})
+	amitabh.Connect(gatsby, moviebuff.Credit{Person: "amitabh-bachchan", Movie: "the-great-gatsby", Roles: []string{"Supporting Actor"}})
+	gatsby.Connect(leo, moviebuff.Credit{Person: "leonardo-dicaprio", PersonName: "Leonardo DiCaprio", Movie: "the-great-gatsby", Roles: []string{"Actor"}})
+	leo.Connect(wolf, moviebuff.Credit{Person: "leonardo-dicaprio", PersonName: "Leonardo DiCaprio",
+		Movie: "the-wolf-of-wall-street", MovieName: "The Wolf of Wall Street", Roles: []string{"Actor"}})
+	wolf.Connect(scorsese, moviebuff.Credit{Person: "martin-scorsese", PersonName: "Martin Scorsese",
+		Movie: "the-wolf-of-wall-street", MovieName: "The Wolf of Wall Street", Roles: []string{"Director"}})
+
+	return graph.Path{amitabh, gatsby, leo, wolf, scorsese}, amitabh, scorsese
+}
+
+func TestRenderTextFollowsTheREADMEFormat(t *testing.T) {
+	path, source, target := gatsbyToScorsese()
+
+	output := &bytes.Buffer{}
+	assert.Nil(t, render(output, formatText, newResult(source, target, path, time.Second)))
+	assert.Equal(t, `
+Degrees of Separation: 2
+
+1. Movie: The Great Gatsby
+Supporting Actor: Amitabh Bachchan
+Actor: Leonardo DiCaprio
+
+2. Movie: The Wolf of Wall Street
+Actor: Leonardo DiCaprio
+Director: Martin Scorsese
+`, output.String())
+}
+
+func TestRenderTextCountsDegreesAsMoviesTraversed(t *testing.T) {
+	person := graph.NewNode("a-person", nil, graph.NewNodeGroup())
+
+	output := &bytes.Buffer{}
+	render(output, formatText, newResult(person, person, graph.Path{person}, 0))
+	assert.Equal(t, "\nDegrees of Separation: 0\n", output.String())
+
+	output.Reset()
+	other := graph.NewNode("another-person", nil, graph.NewNodeGroup())
+	render(output, formatText, newResult(person, other, nil, 0))
+	assert.Equal(t, "\nCould not find a connection between a-person and another-person\n", output.String())
+}
+
+func TestRenderNDJSONWritesTheResultOnASingleLine(t *testing.T) {
+	path, source, target := gatsbyToScorsese()
+	r := newResult(source, target, path, 1500*time.Millisecond)
+	r.Stats.NodesLoaded = 4
+	r.Stats.HTTPRequests = 5
+
+	output := &bytes.Buffer{}
+	assert.Nil(t, render(output, formatNDJSON, r))
+	assert.Equal(t, `{"source":{"slug":"amitabh-bachchan","name":"Amitabh Bachchan"},`+
+		`"target":{"slug":"martin-scorsese","name":"martin-scorsese"},"connected":true,"degrees":2,"hops":[`+
+		`{"movie":{"slug":"the-great-gatsby","name":"The Great Gatsby"},`+
+		`"from":{"slug":"amitabh-bachchan","name":"Amitabh Bachchan","role":"Supporting Actor"},`+
+		`"to":{"slug":"leonardo-dicaprio","name":"Leonardo DiCaprio","role":"Actor"}},`+
+		`{"movie":{"slug":"the-wolf-of-wall-street","name":"The Wolf of Wall Street"},`+
+		`"from":{"slug":"leonardo-dicaprio","name":"Leonardo DiCaprio","role":"Actor"},`+
+		`"to":{"slug":"martin-scorsese","name":"Martin Scorsese","role":"Director"}}],`+
+		`"stats":{"nodesLoaded":4,"httpRequests":5,"elapsedMs":1500}}`+"\n", output.String())
+}
+
+func TestRenderJSONIsIndented(t *testing.T) {
+	person := graph.NewNode("a-person", nil, graph.NewNodeGroup())
+
+	output := &bytes.Buffer{}
+	assert.Nil(t, render(output, formatJSON, newResult(person, person, nil, 0)))
+	assert.Contains(t, output.String(), "\n  \"connected\": false,\n")
+	assert.NotNil(t, render(output, "xml", newResult(person, person, nil, 0)))
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// runServe answers path queries over HTTP, until the server fails.
+func runServe(env *environment, args []string) int {
+	flags := env.newFlagSet("serve", "serve [flags]")
+	addr := flags.String("addr", "localhost:8080", "Address to listen on.")
+	maxDepth := flags.Int("max-depth", 6, "Maximum degrees of separation to search for each query.")
+	timeout := flags.Duration("timeout", time.Minute, "Give up on a query after this long. Queries run until done if 0.")
+	if code := env.parse(flags, args); code >= 0 {
+		return code
+	}
+	if flags.NArg() > 0 {
+		return env.usageError(fmt.Errorf("unexpected arguments: %v", flags.Args()))
+	}
+	if *maxDepth < 1 {
+		return env.usageError(errors.New("-max-depth must be at least 1"))
+	}
+
+	fmt.Fprintf(env.stderr, "Listening on http://%v\n", *addr)
+	err := http.ListenAndServe(*addr, env.pathHandler(*maxDepth, *timeout))
+	fmt.Fprintf(env.stderr, "degrees: %v\n", err)
+	return exitNetworkFailure
+}
+
+// pathHandler answers GET /path?from=<person>&to=<person> with the JSON result of a path query.
+// Adding all=true lists up to 10 chains with the smallest degree of separation, instead of just one,
+// adding k=<number> lists the k shortest chains, including longer ones, and adding search=strongest or search=astar
+// finds the strongest chain instead of the shortest. Credits can be restricted to comma separated lists of roles with
+// allow-roles and deny-roles, chains can avoid a comma separated list of people or movies with avoid, and the
+// shortest chain through a list of them, in order, is found with via.
+// Failed queries are answered with a JSON error, and a status code matching the failure.
+func (env *environment) pathHandler(maxDepth int, timeout time.Duration) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/path", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, errorResponse{"only GET is supported"})
+			return
+		}
+		people := []string{r.URL.Query().Get("from"), r.URL.Query().Get("to")}
+		if err := validateSlugs(people); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+			return
+		}
+
+		ctx := r.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		q := query{from: people[0], to: people[1], maxDepth: maxDepth, all: r.URL.Query().Get("all") == "true", maxChains: 10}
+		if q.search = r.URL.Query().Get("search"); q.search == "" {
+			q.search = searchShortest
+		}
+		allow, deny := &listFlag{}, &listFlag{}
+		allow.Set(r.URL.Query().Get("allow-roles"))
+		deny.Set(r.URL.Query().Get("deny-roles"))
+		q.allow, q.deny = *allow, *deny
+		avoid, via := &listFlag{}, &listFlag{}
+		avoid.Set(r.URL.Query().Get("avoid"))
+		via.Set(r.URL.Query().Get("via"))
+		q.avoid, q.via = *avoid, *via
+		if k := r.URL.Query().Get("k"); k != "" {
+			var err error
+			if q.k, err = strconv.Atoi(k); err != nil || q.k < 0 {
+				writeJSON(w, http.StatusBadRequest, errorResponse{"k must be a non-negative number"})
+				return
+			}
+		}
+		if err := q.validate(); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{err.Error()})
+			return
+		}
+		result, err := env.connect(ctx, q)
+		if err != nil {
+			writeJSON(w, statusFor(err.(*queryError).code), errorResponse{err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+	return mux
+}
+
+// errorResponse is the JSON body of a failed HTTP query.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusFor returns the HTTP status code matching a query's exit code.
+func statusFor(code int) int {
+	switch code {
+	case exitUsage:
+		return http.StatusBadRequest
+	case exitUnknownPerson:
+		return http.StatusNotFound
+	case exitNetworkFailure:
+		return http.StatusBadGateway
+	case exitTimeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestServeAnswersPathQueries(t *testing.T) {
+	dir := writeDump(t)
+	defer os.RemoveAll(dir)
+	env := &environment{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}, dataDir: dir, concurrency: 4, rate: 20, burst: 10, noCache: true}
+	assert.Nil(t, env.setup())
+	server := httptest.NewServer(env.pathHandler(6, time.Minute))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/path?from=amitabh-bachchan&to=leonardo-dicaprio")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	r := result{}
+	json.NewDecoder(response.Body).Decode(&r)
+	response.Body.Close()
+	assert.True(t, r.Connected)
+	assert.Equal(t, 1, r.Degrees)
+	assert.Equal(t, "the-great-gatsby", r.Hops[0].Movie.ID)
+
+	for query, status := range map[string]int{
+		"from=amitabh-bachchan&to=a-recluse":     http.StatusOK,
+		"from=amitabh-bachchan&to=nobody-at-all": http.StatusNotFound,
+		"from=amitabh-bachchan":                  http.StatusBadRequest,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&search=strongest&allow-roles=Actor,Supporting%20Actor": http.StatusOK,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&search=astar&k=2":                                      http.StatusBadRequest,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&search=strongest&all=true":                             http.StatusBadRequest,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&all=true&k=2":                                          http.StatusBadRequest,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&search=fastest":                                        http.StatusBadRequest,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&avoid=the-great-gatsby":                                http.StatusOK,
+		"from=amitabh-bachchan&to=leonardo-dicaprio&via=the-great-gatsby&all=true":                         http.StatusBadRequest,
+	} {
+		response, err := http.Get(server.URL + "/path?" + query)
+		assert.Nil(t, err)
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode != status {
+			t.Errorf("Expected %v for %v, got %v: %s", status, query, response.StatusCode, body)
+		}
+	}
+}
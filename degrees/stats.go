@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// runStats describes the entities in the cache.
+func runStats(env *environment, args []string) int {
+	flags := env.newFlagSet("stats", "stats [flags]")
+	if code := env.parse(flags, args); code >= 0 {
+		return code
+	}
+	if flags.NArg() > 0 {
+		return env.usageError(fmt.Errorf("unexpected arguments: %v", flags.Args()))
+	}
+	cache := env.cache()
+	if cache == nil {
+		return env.usageError(errors.New("stats needs a cache: set -cache-dir, and don't set -no-cache"))
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		fmt.Fprintf(env.stderr, "degrees: could not read the cache in %v: %v\n", env.cacheDir, err)
+		return exitNetworkFailure
+	}
+	fmt.Fprintf(env.stdout, "Cache: %v\n", env.cacheDir)
+	fmt.Fprintf(env.stdout, "Entities: %v (%v people, %v movies)\n", stats.Entities, stats.People, stats.Movies)
+	fmt.Fprintf(env.stdout, "Stale: %v (older than %v)\n", stats.Stale, env.cacheTTL)
+	fmt.Fprintf(env.stdout, "Size: %v bytes\n", stats.Bytes)
+	return exitConnected
+}
@@ -0,0 +1,83 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ShortestPath performs a level-synchronous breadth first search from the source node to the target node.
+// Every level of the search is loaded completely before moving on to the next, so the first path found is a shortest one.
+// Nodes are visited at most once, and are lazily loaded only when the frontier reaches them.
+// Returns nil if the target can not be reached within the Search's maximum depth, along with the Search's error if it was stopped.
+func (s *Search) ShortestPath(source, target *Node) (Path, error) {
+	path, err := s.shortestPath(source, target, s.maxDepth, nil)
+	if path != nil {
+		s.foundPaths(1)
+	}
+	return path, err
+}
+
+// blocklist lists the Nodes, and the Edges between pairs of Node IDs, that a search must not traverse.
+type blocklist struct {
+	nodes map[string]bool
+	edges map[[2]string]bool
+}
+
+func (b *blocklist) blocks(from, to *Node) bool {
+	return b != nil && (b.nodes[to.ID] || b.edges[[2]string{from.ID, to.ID}])
+}
+
+// shortestPath is ShortestPath, searching up to maxDepth hops, and never traversing anything on the blocklist.
+// Neighbours are expanded in order of their IDs, so that the same shortest path is found every time when there are several.
+func (s *Search) shortestPath(source, target *Node, maxDepth int, blocked *blocklist) (Path, error) {
+	s.visit(source)
+	if source.Equal(target) {
+		return Path{source}, nil
+	}
+
+	parents := map[string]*Node{source.ID: nil}
+	frontier := []*Node{source}
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		if debug {
+			fmt.Printf("BFS depth %v: %v node(s) in frontier\n", depth, len(frontier))
+		}
+		s.group.loader.LoadAll(s.ctx, frontier)
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		s.expanded(len(frontier))
+
+		next := []*Node{}
+		for _, node := range frontier {
+			for _, neighbour := range sortedNeighbours(node) {
+				if _, visited := parents[neighbour.ID]; visited || blocked.blocks(node, neighbour) || !s.traverses(node, neighbour) {
+					continue
+				}
+				parents[neighbour.ID] = node
+				s.visit(neighbour)
+				if neighbour.Equal(target) {
+					return tracePath(parents, neighbour), nil
+				}
+				next = append(next, neighbour)
+			}
+		}
+		frontier = next
+	}
+	return nil, nil
+}
+
+// sortedNeighbours returns a snapshot of a Node's neighbours, ordered by their IDs.
+func sortedNeighbours(node *Node) []*Node {
+	neighbours := node.Neighbours()
+	sort.Slice(neighbours, func(i, j int) bool { return neighbours[i].ID < neighbours[j].ID })
+	return neighbours
+}
+
+// tracePath walks the parent links recorded by a search back from the given node, and returns the path leading up to it.
+func tracePath(parents map[string]*Node, node *Node) Path {
+	path := Path{}
+	for ; node != nil; node = parents[node.ID] {
+		path = append(Path{node}, path...)
+	}
+	return path
+}
@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestShortestPathComputation(t *testing.T) {
+	/*
+	     G-----F
+	    / |    |
+	   /  |    |    H---I
+	   A--C----E        |
+	   \  |    /        J
+	    \ |   /
+	     B---D
+	*/
+	defaultNodeGroup = NewNodeGroup()
+	a := NewNode("A")
+	b := NewNode("B")
+	c := NewNode("C")
+	d := NewNode("D")
+	e := NewNode("E")
+	f := NewNode("F")
+	g := NewNode("G")
+	h := NewNode("H")
+	i := NewNode("I")
+	j := NewNode("J")
+
+	a.Connect(b)
+	a.Connect(c)
+	a.Connect(g)
+	b.Connect(c)
+	b.Connect(d)
+	c.Connect(g)
+	d.Connect(e)
+	e.Connect(c)
+	e.Connect(f)
+	f.Connect(g)
+	h.Connect(i)
+	i.Connect(j)
+
+	aToF := a.PathsTo(f, true)
+	assert.Equal(t, 1, len(aToF))
+	if len(aToF) == 1 {
+		assert.Equal(t, Path{a, g, f}.String(), aToF[0].String())
+	}
+
+	bToF := b.PathsTo(f, true)
+	assert.Equal(t, 1, len(bToF))
+	if len(bToF) == 1 {
+		assert.Equal(t, 4, len(bToF[0]))
+	}
+
+	aToA := a.PathsTo(a, true)
+	assert.Equal(t, 1, len(aToA))
+	if len(aToA) == 1 {
+		assert.Equal(t, Path{a}.String(), aToA[0].String())
+	}
+
+	aToJ := a.PathsTo(j, true)
+	assert.Equal(t, 0, len(aToJ))
+}
+
+func TestShortestPathHonoursMaxRecursionDepth(t *testing.T) {
+	group := NewNodeGroup(2)
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+
+	a.Connect(b)
+	b.Connect(c)
+	c.Connect(d)
+
+	assert.Equal(t, 1, len(a.PathsTo(c, true)))
+	assert.Equal(t, 0, len(a.PathsTo(d, true)))
+}
+
+func TestShortestPathLazilyLoadsFrontier(t *testing.T) {
+	group := NewNodeGroup()
+	loaded := []string{}
+	var loader NodeFetcher
+	loader = func(n *Node) error {
+		loaded = append(loaded, n.ID)
+		n.SetData(true)
+		switch n.ID {
+		case "A":
+			n.Connect(NewNode("B", loader, group))
+		case "B":
+			n.Connect(NewNode("C", loader, group))
+		case "C":
+			n.Connect(NewNode("D", loader, group))
+		}
+		return nil
+	}
+	a := NewNode("A", loader, group)
+	c := NewNode("C", loader, group)
+
+	aToC := a.PathsTo(c, true)
+	assert.Equal(t, 1, len(aToC))
+	if len(aToC) == 1 {
+		assert.Equal(t, "A -> B -> C", aToC[0].String())
+	}
+	assert.Equal(t, []string{"A", "B"}, loaded)
+}
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// searchSide tracks one of the two half searches of a bidirectional search.
+// The backward side searches from the target, so it traverses Edges in reverse.
+type searchSide struct {
+	parents  map[string]*Node
+	frontier []*Node
+	depth    int
+	backward bool
+}
+
+func newSearchSide(root *Node, backward bool) *searchSide {
+	return &searchSide{parents: map[string]*Node{root.ID: nil}, frontier: []*Node{root}, backward: backward}
+}
+
+// BidirectionalPathsTo computes a shortest path from the current node to the target node, by searching breadth first
+// from both ends at once, and stitching the path together where the two searches meet.
+// The side with the smaller frontier is expanded at each step, and nodes are lazily loaded as the frontiers reach them.
+// It returns an empty slice when no path is available within the NodeGroup's maximum recursion depth.
+func (n *Node) BidirectionalPathsTo(target *Node) []Path {
+	paths, _ := n.BidirectionalPathsToContext(context.Background(), target)
+	return paths
+}
+
+// BidirectionalPathsToContext is BidirectionalPathsTo, but stops loading Nodes and returns as soon as the context is done.
+// It returns an empty slice along with the context's error when the search could not be completed.
+func (n *Node) BidirectionalPathsToContext(ctx context.Context, target *Node) ([]Path, error) {
+	search := NewSearch(ctx, n.group)
+	defer search.Cancel()
+	path, err := search.BidirectionalPath(n, target)
+	if path == nil {
+		return []Path{}, err
+	}
+	return []Path{path}, nil
+}
+
+// BidirectionalPath computes a shortest path from the source node to the target node, searching from both ends at once.
+// Returns nil if no path is available within the Search's maximum depth, along with the Search's error if it was stopped.
+func (s *Search) BidirectionalPath(source, target *Node) (Path, error) {
+	s.visit(source)
+	if source.Equal(target) {
+		s.foundPaths(1)
+		return Path{source}, nil
+	}
+
+	s.visit(target)
+	forward := newSearchSide(source, false)
+	backward := newSearchSide(target, true)
+	for forward.depth+backward.depth < s.maxDepth {
+		// A side with nothing left to expand can't reach any more nodes, so the two sides will never meet
+		if len(forward.frontier) == 0 || len(backward.frontier) == 0 {
+			return nil, nil
+		}
+		side, other := forward, backward
+		if backward.expandsBefore(forward) {
+			side, other = backward, forward
+		}
+		if debug {
+			fmt.Printf("Bidirectional BFS depth %v+%v: expanding %v node(s)\n", forward.depth, backward.depth, len(side.frontier))
+		}
+
+		meeting, err := side.expand(s, other)
+		if err != nil {
+			return nil, err
+		}
+		if meeting != nil {
+			s.foundPaths(1)
+			return stitchPaths(tracePath(forward.parents, meeting), tracePath(backward.parents, meeting)), nil
+		}
+	}
+	return nil, nil
+}
+
+// expandsBefore returns true if this side should be expanded ahead of the other side.
+// The smaller frontier goes first, and the shallower side breaks ties so that the two searches alternate.
+func (side *searchSide) expandsBefore(other *searchSide) bool {
+	if len(side.frontier) == len(other.frontier) {
+		return side.depth < other.depth
+	}
+	return len(side.frontier) < len(other.frontier)
+}
+
+// expand loads the current frontier and advances the search by one level.
+// Returns the node at which the shortest path through this level meets the other side of the search, or nil if they don't meet.
+// Returns the Search's error if it is stopped before the frontier could be loaded.
+func (side *searchSide) expand(s *Search, other *searchSide) (*Node, error) {
+	s.group.loader.LoadAll(s.ctx, side.frontier)
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	s.expanded(len(side.frontier))
+
+	var meeting *Node
+	meetingLength := 0
+	next := []*Node{}
+	for _, node := range side.frontier {
+		for _, neighbour := range node.Neighbours() {
+			if _, visited := side.parents[neighbour.ID]; visited {
+				continue
+			}
+			from, to := node, neighbour
+			if side.backward {
+				from, to = neighbour, node
+			}
+			if !s.traverses(from, to) {
+				continue
+			}
+			side.parents[neighbour.ID] = node
+			s.visit(neighbour)
+			next = append(next, neighbour)
+
+			if _, reached := other.parents[neighbour.ID]; reached {
+				length := len(tracePath(other.parents, neighbour))
+				if meeting == nil || length < meetingLength {
+					meeting, meetingLength = neighbour, length
+				}
+			}
+		}
+	}
+	side.frontier = next
+	side.depth++
+	return meeting, nil
+}
+
+// stitchPaths joins a path leading up to a meeting node with a path leading back from the target to the same meeting node.
+func stitchPaths(toMeeting, fromTarget Path) Path {
+	path := append(Path{}, toMeeting...)
+	for i := len(fromTarget) - 2; i >= 0; i-- {
+		path = append(path, fromTarget[i])
+	}
+	return path
+}
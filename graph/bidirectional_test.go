@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBidirectionalPathComputation(t *testing.T) {
+	/*
+	     G-----F
+	    / |    |
+	   /  |    |    H---I
+	   A--C----E        |
+	   \  |    /        J
+	    \ |   /
+	     B---D
+	*/
+	defaultNodeGroup = NewNodeGroup()
+	a := NewNode("A")
+	b := NewNode("B")
+	c := NewNode("C")
+	d := NewNode("D")
+	e := NewNode("E")
+	f := NewNode("F")
+	g := NewNode("G")
+	h := NewNode("H")
+	i := NewNode("I")
+	j := NewNode("J")
+
+	a.Connect(b)
+	a.Connect(c)
+	a.Connect(g)
+	b.Connect(c)
+	b.Connect(d)
+	c.Connect(g)
+	d.Connect(e)
+	e.Connect(c)
+	e.Connect(f)
+	f.Connect(g)
+	h.Connect(i)
+	i.Connect(j)
+
+	aToF := a.BidirectionalPathsTo(f)
+	assert.Equal(t, 1, len(aToF))
+	if len(aToF) == 1 {
+		assert.Equal(t, Path{a, g, f}.String(), aToF[0].String())
+	}
+
+	dToG := d.BidirectionalPathsTo(g)
+	assert.Equal(t, 1, len(dToG))
+	if len(dToG) == 1 {
+		assert.Equal(t, 4, len(dToG[0]))
+		assert.True(t, dToG[0][0].Equal(d))
+		assert.True(t, dToG[0][3].Equal(g))
+	}
+
+	hToJ := h.BidirectionalPathsTo(j)
+	assert.Equal(t, 1, len(hToJ))
+	if len(hToJ) == 1 {
+		assert.Equal(t, Path{h, i, j}.String(), hToJ[0].String())
+	}
+
+	aToA := a.BidirectionalPathsTo(a)
+	assert.Equal(t, 1, len(aToA))
+
+	aToJ := a.BidirectionalPathsTo(j)
+	assert.Equal(t, 0, len(aToJ))
+}
+
+func TestBidirectionalPathHonoursMaxRecursionDepth(t *testing.T) {
+	group := NewNodeGroup(2)
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+
+	a.Connect(b)
+	b.Connect(c)
+	c.Connect(d)
+
+	assert.Equal(t, 1, len(a.BidirectionalPathsTo(c)))
+	assert.Equal(t, 0, len(a.BidirectionalPathsTo(d)))
+}
+
+func TestBidirectionalPathLazilyLoadsBothEnds(t *testing.T) {
+	/*
+	   A--B--C--D--E, with each node only learning its neighbours once loaded.
+	*/
+	group := NewNodeGroup()
+	edges := map[string][]string{"A": {"B"}, "B": {"A", "C"}, "C": {"B", "D"}, "D": {"C", "E"}, "E": {"D"}}
+	loaded := map[string]bool{}
+	var loader NodeFetcher
+	loader = func(n *Node) error {
+		loaded[n.ID] = true
+		n.SetData(true)
+		for _, id := range edges[n.ID] {
+			n.Connect(NewNode(id, loader, group))
+		}
+		return nil
+	}
+	a := NewNode("A", loader, group)
+	e := NewNode("E", loader, group)
+
+	aToE := a.BidirectionalPathsTo(e)
+	assert.Equal(t, 1, len(aToE))
+	if len(aToE) == 1 {
+		assert.Equal(t, "A -> B -> C -> D -> E", aToE[0].String())
+	}
+	assert.True(t, loaded["A"])
+	assert.True(t, loaded["E"])
+	assert.False(t, loaded["C"])
+}
+
+func TestBidirectionalPathStopsWhenEitherSideRunsOut(t *testing.T) {
+	/*
+	   S, with no neighbours, and T--N1--N2--...--N30
+	*/
+	group := NewNodeGroup(10)
+	var loader NodeFetcher
+	loader = func(n *Node) error {
+		n.SetData(true)
+		switch {
+		case n.ID == "T":
+			n.Connect(NewNode("N1", loader, group))
+		case strings.HasPrefix(n.ID, "N"):
+			if i, _ := strconv.Atoi(n.ID[1:]); i < 30 {
+				n.Connect(NewNode(fmt.Sprintf("N%v", i+1), loader, group))
+			}
+		}
+		return nil
+	}
+	s := NewNode("S", loader, group)
+	target := NewNode("T", loader, group)
+
+	assert.Equal(t, 0, len(s.BidirectionalPathsTo(target)))
+	assert.True(t, group.Loader().Loaded() <= 2, "Expected at most S and T to be loaded, but %v nodes were", group.Loader().Loaded())
+}
+
+func TestBidirectionalPathsToContextStopsWhenDeadlineExpires(t *testing.T) {
+	group := NewNodeGroup()
+	var blockingLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	a := NewNode("A", blockingLoader, group)
+	b := NewNode("B", blockingLoader, group)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	paths, err := a.BidirectionalPathsToContext(ctx, b)
+	assert.Equal(t, 0, len(paths))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,82 @@
+package graph
+
+// defaultEdgeWeight is the weight of Edges connected without one.
+const defaultEdgeWeight = 1.0
+
+// Edge is the connection between a Node and one of its neighbours, along with any metadata and weight attached to it by Connect.
+// Weighted searches treat the weight as the cost of traversing the Edge, so lower weights make for stronger connections.
+type Edge struct {
+	From   *Node
+	To     *Node
+	Data   interface{}
+	Weight float64
+}
+
+// Merger is Edge metadata that can combine with more metadata for the same Edge, when its Nodes are connected again.
+// Merge must not modify either piece of metadata, and the result must not depend on the order they are merged in.
+type Merger interface {
+	Merge(other interface{}) interface{}
+}
+
+// String returns a string representation of the Edge.
+func (e Edge) String() string {
+	return e.From.String() + " -> " + e.To.String()
+}
+
+// EdgeTo returns the Edge from the current node to the given neighbour.
+// Returns false if the given node is not a neighbour of the current node.
+func (n *Node) EdgeTo(other *Node) (Edge, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, neighbour := range n.neighbours {
+		if other.Equal(neighbour) {
+			return n.edgeTo(neighbour), true
+		}
+	}
+	return Edge{}, false
+}
+
+// Edges returns a snapshot of the Edges from the current node to each of its immediate neighbours in a thread-safe manner.
+func (n *Node) Edges() []Edge {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	edges := []Edge{}
+	for _, neighbour := range n.neighbours {
+		edges = append(edges, n.edgeTo(neighbour))
+	}
+	return edges
+}
+
+// edgeTo must be called with the Node locked.
+func (n *Node) edgeTo(neighbour *Node) Edge {
+	weight, weighted := n.edgeWeights[neighbour.ID]
+	if !weighted {
+		weight = defaultEdgeWeight
+	}
+	return Edge{From: n, To: neighbour, Data: n.edgeData[neighbour.ID], Weight: weight}
+}
+
+// mergeEdgeData must be called with the Node locked.
+func (n *Node) mergeEdgeData(other *Node, data interface{}) {
+	if n.edgeData == nil {
+		n.edgeData = make(map[string]interface{})
+	}
+	existing, present := n.edgeData[other.ID]
+	if !present {
+		n.edgeData[other.ID] = data
+		return
+	}
+	if merger, merges := existing.(Merger); merges {
+		n.edgeData[other.ID] = merger.Merge(data)
+	}
+}
+
+// mergeEdgeWeight keeps the lowest weight of the Edge. Must be called with the Node locked.
+func (n *Node) mergeEdgeWeight(other *Node, weight float64) {
+	if n.edgeWeights == nil {
+		n.edgeWeights = make(map[string]float64)
+	}
+	if existing, present := n.edgeWeights[other.ID]; !present || weight < existing {
+		n.edgeWeights[other.ID] = weight
+	}
+}
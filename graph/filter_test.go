@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// roads creates a graph of cities connected by roads labelled with their kind, in a new NodeGroup.
+/*
+   A--highway--B--highway--D
+   |                       |
+   +--track--C-----track---+
+*/
+func roads() (*NodeGroup, *Node, *Node) {
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+	a.Connect(b, "highway")
+	b.Connect(d, "highway")
+	a.Connect(c, "track")
+	c.Connect(d, "track")
+	return group, a, d
+}
+
+func TestSearchesOnlyTraverseFilteredEdges(t *testing.T) {
+	group, a, d := roads()
+	onlyTracks := func(edge Edge) bool { return edge.Data == "track" }
+
+	searches := map[string]func(s *Search) []Path{
+		"PathsTo": func(s *Search) []Path {
+			paths, _ := s.PathsTo(a, d)
+			return paths
+		},
+		"ShortestPath": func(s *Search) []Path {
+			path, _ := s.ShortestPath(a, d)
+			return []Path{path}
+		},
+		"BidirectionalPath": func(s *Search) []Path {
+			path, _ := s.BidirectionalPath(a, d)
+			return []Path{path}
+		},
+		"AllShortestPaths": func(s *Search) []Path {
+			paths, _ := s.AllShortestPaths(a, d)
+			return paths
+		},
+		"KShortestPaths": func(s *Search) []Path {
+			paths, _ := s.KShortestPaths(a, d, 5)
+			return paths
+		},
+		"WeightedPath": func(s *Search) []Path {
+			path, _, _ := s.WeightedPath(a, d)
+			return []Path{path}
+		},
+	}
+	for name, search := range searches {
+		s := NewSearch(context.Background(), group)
+		s.SetFilter(onlyTracks)
+		if paths := fmt.Sprint(search(s)); paths != "[A -> C -> D]" {
+			t.Errorf("Expected %v to only find [A -> C -> D], got %v", name, paths)
+		}
+	}
+}
+
+func TestFiltersCanInspectNodeData(t *testing.T) {
+	group, a, d := roads()
+	NewNode("B", nil, group).SetData("closed")
+
+	s := NewSearch(context.Background(), group)
+	s.SetFilter(func(edge Edge) bool { return edge.To.Data() != "closed" })
+	path, _ := s.BidirectionalPath(a, d)
+	assert.Equal(t, "A -> C -> D", path.String())
+}
+
+func TestBidirectionalSearchesFilterEdgesInTheirDirection(t *testing.T) {
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	a.Connect(b)
+	b.Connect(c)
+
+	s := NewSearch(context.Background(), group)
+	s.SetFilter(func(edge Edge) bool { return edge.From.ID < edge.To.ID })
+	path, _ := s.BidirectionalPath(a, c)
+	assert.Equal(t, "A -> B -> C", path.String())
+
+	s = NewSearch(context.Background(), group)
+	s.SetFilter(func(edge Edge) bool { return edge.From.ID < edge.To.ID })
+	path, _ = s.BidirectionalPath(c, a)
+	assert.Nil(t, path)
+}
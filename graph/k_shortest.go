@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"sort"
+)
+
+// KShortestPathsTo computes up to k loopless paths from the current node to the target node, shortest first.
+// Paths of the same length are ordered by the IDs of their nodes, so the first path is the one ShortestPath finds.
+// It returns fewer than k paths when there aren't as many within the NodeGroup's maximum recursion depth.
+func (n *Node) KShortestPathsTo(target *Node, k int) []Path {
+	paths, _ := n.KShortestPathsToContext(context.Background(), target, k)
+	return paths
+}
+
+// KShortestPathsToContext is KShortestPathsTo, but stops loading Nodes and returns as soon as the context is done.
+// It returns the paths found so far along with the context's error when the search could not be completed.
+func (n *Node) KShortestPathsToContext(ctx context.Context, target *Node, k int) ([]Path, error) {
+	search := NewSearch(ctx, n.group)
+	defer search.Cancel()
+	return search.KShortestPaths(n, target, k)
+}
+
+// KShortestPaths computes up to k loopless paths from the source node to the target node, shortest first, using Yen's algorithm.
+// Starting from a shortest path, every next path is the shortest of the candidates found by branching off the paths
+// already found: for each node along the latest path, a breadth first search looks for a detour to the target that
+// avoids the edges already taken from that node by paths sharing the same prefix, and the nodes in the prefix itself.
+// Nodes are lazily loaded as the searches reach them. Paths longer than the Search's maximum depth are never returned.
+// Candidates are ranked the same way the breadth first searches choose between paths of the same length, by the IDs
+// of their nodes, so the paths returned for k are always the first k of those returned for any larger k.
+func (s *Search) KShortestPaths(source, target *Node, k int) ([]Path, error) {
+	if k < 1 {
+		return []Path{}, nil
+	}
+	first, err := s.shortestPath(source, target, s.maxDepth, nil)
+	if first == nil {
+		return []Path{}, err
+	}
+
+	shortest := []Path{first}
+	candidates := []Path{}
+	for len(shortest) < k {
+		previous := shortest[len(shortest)-1]
+		for i := 0; i+1 < len(previous); i++ {
+			spurNode, root := previous[i], previous[:i+1]
+			blocked := &blocklist{nodes: make(map[string]bool), edges: make(map[[2]string]bool)}
+			for _, path := range shortest {
+				if len(path) > i+1 && path[:i+1].Equal(root) {
+					blocked.edges[[2]string{path[i].ID, path[i+1].ID}] = true
+				}
+			}
+			for _, node := range root[:i] {
+				blocked.nodes[node.ID] = true
+			}
+
+			spur, err := s.shortestPath(spurNode, target, s.maxDepth-i, blocked)
+			if err != nil {
+				s.foundPaths(len(shortest))
+				return shortest, err
+			}
+			if spur == nil {
+				continue
+			}
+			candidate := append(append(Path{}, root[:i]...), spur...)
+			if !containsPath(candidates, candidate) && !containsPath(shortest, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		sort.Sort(byPathRank(candidates))
+		shortest = append(shortest, candidates[0])
+		candidates = candidates[1:]
+	}
+	s.foundPaths(len(shortest))
+	return shortest, nil
+}
+
+// byPathRank orders paths by length, and paths of the same length by the IDs of their nodes, in order.
+// This is the order in which the breadth first search in shortestPath prefers paths of the same length.
+type byPathRank []Path
+
+func (a byPathRank) Len() int      { return len(a) }
+func (a byPathRank) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byPathRank) Less(i, j int) bool {
+	if len(a[i]) != len(a[j]) {
+		return len(a[i]) < len(a[j])
+	}
+	for n := range a[i] {
+		if a[i][n].ID != a[j][n].ID {
+			return a[i][n].ID < a[j][n].ID
+		}
+	}
+	return false
+}
+
+func containsPath(paths []Path, path Path) bool {
+	for _, other := range paths {
+		if other.Equal(path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// ladder creates the graph below in a new NodeGroup, and returns its nodes by ID.
+/*
+     B---E
+    / \ / \
+   A   X   G---H
+    \ / \ /
+     C---F
+*/
+func ladder(args ...interface{}) map[string]*Node {
+	group := NewNodeGroup(args...)
+	nodes := make(map[string]*Node)
+	for _, id := range []string{"A", "B", "C", "E", "F", "G", "H", "X"} {
+		nodes[id] = NewNode(id, nil, group)
+	}
+	for _, edge := range []string{"AB", "AC", "BE", "BX", "CX", "CF", "EX", "FX", "EG", "FG", "GH"} {
+		nodes[edge[:1]].Connect(nodes[edge[1:]])
+	}
+	return nodes
+}
+
+func TestKShortestPathsComputation(t *testing.T) {
+	nodes := ladder()
+
+	paths := nodes["A"].KShortestPathsTo(nodes["G"], 2)
+	assert.Equal(t, "[A -> B -> E -> G A -> C -> F -> G]", fmt.Sprint(paths))
+
+	paths = nodes["A"].KShortestPathsTo(nodes["G"], 100)
+	assert.Equal(t, 12, len(paths))
+	for i := 1; i < len(paths); i++ {
+		if len(paths[i]) < len(paths[i-1]) {
+			t.Errorf("Expected paths ordered by length, got %v before %v", paths[i-1], paths[i])
+		}
+	}
+	for _, path := range paths {
+		assert.True(t, path[0] == nodes["A"] && path[len(path)-1] == nodes["G"])
+	}
+	assert.Equal(t, 12, len(deDuplicatePaths(paths)))
+
+	assert.Equal(t, paths, nodes["A"].KShortestPathsTo(nodes["G"], 100))
+}
+
+func TestKShortestPathsAreTheFirstKInRankOrder(t *testing.T) {
+	/*
+	     A
+	    / \
+	   S-B-T
+	    \ /
+	     C
+	*/
+	group := NewNodeGroup()
+	s := NewNode("S", nil, group)
+	target := NewNode("T", nil, group)
+	for _, id := range []string{"C", "A", "B"} {
+		middle := NewNode(id, nil, group)
+		s.Connect(middle)
+		middle.Connect(target)
+	}
+
+	assert.Equal(t, "[S -> A -> T]", fmt.Sprint(s.KShortestPathsTo(target, 1)))
+	assert.Equal(t, "[S -> A -> T S -> B -> T]", fmt.Sprint(s.KShortestPathsTo(target, 2)))
+	assert.Equal(t, "[S -> A -> T S -> B -> T S -> C -> T]", fmt.Sprint(s.KShortestPathsTo(target, 3)))
+
+	nodes := ladder()
+	all := nodes["A"].KShortestPathsTo(nodes["G"], 100)
+	for k := 1; k <= len(all); k++ {
+		assert.Equal(t, fmt.Sprint(all[:k]), fmt.Sprint(nodes["A"].KShortestPathsTo(nodes["G"], k)))
+	}
+	path, _ := NewSearch(context.Background(), nodes["A"].Group()).ShortestPath(nodes["A"], nodes["G"])
+	assert.Equal(t, path.String(), all[0].String())
+}
+
+func TestKShortestPathsAreLoopless(t *testing.T) {
+	nodes := ladder()
+
+	for _, path := range nodes["A"].KShortestPathsTo(nodes["H"], 100) {
+		seen := make(map[string]bool)
+		for _, node := range path {
+			if seen[node.ID] {
+				t.Errorf("Expected loopless paths, but %v visits %v more than once", path, node)
+			}
+			seen[node.ID] = true
+		}
+	}
+}
+
+func TestKShortestPathsRespectsMaxDepth(t *testing.T) {
+	nodes := ladder(3)
+
+	paths := nodes["A"].KShortestPathsTo(nodes["G"], 100)
+	assert.Equal(t, "[A -> B -> E -> G A -> C -> F -> G]", fmt.Sprint(paths))
+	assert.Equal(t, 0, len(nodes["A"].KShortestPathsTo(nodes["H"], 100)))
+	assert.Equal(t, 0, len(nodes["A"].KShortestPathsTo(nodes["G"], 0)))
+
+	search := NewSearch(context.Background(), nodes["A"].Group())
+	search.Cancel()
+	paths, err := search.KShortestPaths(nodes["A"], nodes["G"], 3)
+	assert.Equal(t, 0, len(paths))
+	assert.Equal(t, context.Canceled, err)
+}
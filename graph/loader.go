@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultLoaderWorkers = 16
+
+// Loader lazily loads Nodes by dispatching their NodeFetchers through a bounded pool of workers.
+// Concurrent requests to load the same Node ID share a single fetch.
+// Failed fetches are retried according to a RetryPolicy, and Node IDs that failed permanently are never fetched again.
+type Loader struct {
+	loaded   int64
+	failed   int64
+	workers  int
+	retry    RetryPolicy
+	slots    chan struct{}
+	inflight map[string]*loadCall
+	missing  map[string]error
+	lock     sync.Mutex
+}
+
+// loadCall tracks a load that is in progress, so that concurrent requests for the same Node can wait on it.
+type loadCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewLoader creates a new Loader
+// Parameter 1: workers - Maximum number of NodeFetchers to run concurrently. Defaults to 16.
+// Parameter 2: retry - RetryPolicy for failed fetches. Defaults to DefaultRetryPolicy.
+func NewLoader(args ...interface{}) *Loader {
+	workers := defaultLoaderWorkers
+	retry := DefaultRetryPolicy
+	if len(args) > 0 {
+		workers = args[0].(int)
+	}
+	if len(args) > 1 {
+		retry = args[1].(RetryPolicy)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &Loader{workers: workers,
+		retry:    retry,
+		slots:    make(chan struct{}, workers),
+		inflight: make(map[string]*loadCall),
+		missing:  make(map[string]error)}
+}
+
+// Loaded returns the number of Nodes this Loader has successfully loaded.
+func (l *Loader) Loaded() int {
+	return int(atomic.LoadInt64(&l.loaded))
+}
+
+// Failed returns the number of Nodes this Loader gave up loading after transient errors.
+// Nodes that failed permanently, or whose load was cancelled, are not counted.
+func (l *Loader) Failed() int {
+	return int(atomic.LoadInt64(&l.failed))
+}
+
+// Missing returns the permanent error a Node ID failed to load with, or nil if it hasn't failed permanently.
+func (l *Loader) Missing(id string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.missing[id]
+}
+
+// Load lazily loads the given Node if it has no data yet.
+// If the Node is already being loaded, Load waits for that load to finish instead of fetching it again.
+// In-flight loads are checked before the Node's data, since a fetcher may set the data before it finishes loading the Node.
+func (l *Loader) Load(ctx context.Context, n *Node) error {
+	l.lock.Lock()
+	if call, loading := l.inflight[n.ID]; loading {
+		l.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-call.done:
+		}
+		if call.err == nil && !n.HasData() {
+			// A different Node instance with the same ID was loaded
+			return l.Load(ctx, n)
+		}
+		if isContextError(call.err) && ctx.Err() == nil {
+			// The load was stopped by its caller's context, not ours, so try again
+			return l.Load(ctx, n)
+		}
+		return call.err
+	}
+	if n.HasData() {
+		l.lock.Unlock()
+		return nil
+	}
+	if err, missing := l.missing[n.ID]; missing {
+		l.lock.Unlock()
+		return err
+	}
+	call := &loadCall{done: make(chan struct{})}
+	l.inflight[n.ID] = call
+	l.lock.Unlock()
+
+	call.err = l.load(ctx, n)
+	switch {
+	case call.err == nil:
+		atomic.AddInt64(&l.loaded, 1)
+	case !IsPermanent(call.err) && ctx.Err() == nil:
+		atomic.AddInt64(&l.failed, 1)
+	}
+
+	l.lock.Lock()
+	delete(l.inflight, n.ID)
+	if IsPermanent(call.err) {
+		l.missing[n.ID] = call.err
+	}
+	l.lock.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// LoadAll lazily loads all the given Nodes, and returns once they have all been attempted.
+// Nodes that fail to load are left without data. No new loads are started once the context is done.
+func (l *Loader) LoadAll(ctx context.Context, nodes []*Node) {
+	pending := make(chan *Node)
+	var wg sync.WaitGroup
+	for i := 0; i < l.workers && i < len(nodes); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for node := range pending {
+				if err := l.Load(ctx, node); err != nil && debug {
+					fmt.Printf(">>>>>>>>>>>>>>>>> Failed to load %v: %v\n", node.ID, err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, node := range nodes {
+		if node.HasData() {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			break feed
+		case pending <- node:
+		}
+	}
+	close(pending)
+	wg.Wait()
+}
+
+// load fetches the Node's data, retrying failed fetches as allowed by the Loader's RetryPolicy.
+// Each fetch attempt occupies one of the Loader's worker slots.
+func (l *Loader) load(ctx context.Context, n *Node) error {
+	loadAttempt := 0
+	for !n.HasData() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if debug {
+			fmt.Printf("Loading %v. Attempt %v\n", n.ID, loadAttempt)
+		}
+		err := l.fetch(ctx, n)
+		// Retry loading node after a backoff if there was a transient error while loading
+		if err != nil {
+			if !l.retry.ShouldRetry(loadAttempt, err) {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(l.retry.Delay(loadAttempt)):
+			}
+			loadAttempt++
+		}
+	}
+	return nil
+}
+
+func (l *Loader) fetch(ctx context.Context, n *Node) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case l.slots <- struct{}{}:
+	}
+	defer func() { <-l.slots }()
+	return n.fetch(ctx)
+}
+
+// isContextError returns true if the error is the result of a context being cancelled or timing out.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoaderConstruction(t *testing.T) {
+	assert.Equal(t, defaultLoaderWorkers, NewLoader().workers)
+	assert.Equal(t, 4, NewLoader(4).workers)
+	assert.Equal(t, 1, NewLoader(0).workers)
+}
+
+func TestLoaderFetchesConcurrentRequestsForTheSameNodeOnlyOnce(t *testing.T) {
+	var fetches int32
+	release := make(chan bool)
+	var slowLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		n.SetData(true)
+		return nil
+	}
+	node := NewNode("A", slowLoader, NewNodeGroup())
+	loader := NewLoader()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, loader.Load(context.Background(), node))
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+	assert.True(t, node.HasData())
+	assert.Equal(t, 1, loader.Loaded())
+}
+
+func TestLoaderWaitsForLoadsThatSetDataBeforeTheyFinish(t *testing.T) {
+	group := NewNodeGroup()
+	dataSet, release := make(chan bool), make(chan bool)
+	var eagerLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		n.SetData(true)
+		dataSet <- true
+		<-release
+		n.Connect(NewNode("B", nil, group))
+		return nil
+	}
+	node := NewNode("A", eagerLoader, group)
+	loader := NewLoader()
+
+	first := make(chan error)
+	go func() { first <- loader.Load(context.Background(), node) }()
+	<-dataSet
+	second := make(chan int, 1)
+	go func() {
+		assert.Nil(t, loader.Load(context.Background(), node))
+		second <- len(node.Neighbours())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	assert.Nil(t, <-first)
+	assert.Equal(t, 1, <-second)
+}
+
+func TestLoaderBoundsConcurrentFetches(t *testing.T) {
+	var running, maxRunning int32
+	var countingLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			observed := atomic.LoadInt32(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		n.SetData(true)
+		return nil
+	}
+	group := NewNodeGroup()
+	nodes := []*Node{}
+	for _, id := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J"} {
+		nodes = append(nodes, NewNode(id, countingLoader, group))
+	}
+
+	NewLoader(3).LoadAll(context.Background(), nodes)
+
+	for _, node := range nodes {
+		assert.True(t, node.HasData())
+	}
+	assert.True(t, atomic.LoadInt32(&maxRunning) <= 3)
+}
+
+func TestLoaderStopsWaitingWhenContextIsDone(t *testing.T) {
+	var blockingLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	group := NewNodeGroup()
+	nodes := []*Node{NewNode("A", blockingLoader, group), NewNode("B", blockingLoader, group)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	NewLoader(1).LoadAll(ctx, nodes)
+
+	assert.False(t, nodes[0].HasData())
+	assert.False(t, nodes[1].HasData())
+}
+
+func TestLoaderRetriesLoadsCancelledByAnotherCaller(t *testing.T) {
+	var fetches int32
+	started := make(chan bool, 1)
+	var cancellableLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			started <- true
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		n.SetData(true)
+		return nil
+	}
+	node := NewNode("A", cancellableLoader, NewNodeGroup())
+	loader := NewLoader()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() { cancelled <- loader.Load(ctx, node) }()
+	<-started
+	waiting := make(chan error)
+	go func() { waiting <- loader.Load(context.Background(), node) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	assert.Equal(t, context.Canceled, <-cancelled)
+	assert.Nil(t, <-waiting)
+	assert.True(t, node.HasData())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestLoaderRetriesTransientFailures(t *testing.T) {
+	fetches := 0
+	var flakyLoader NodeFetcher = func(n *Node) error {
+		fetches++
+		if fetches < 3 {
+			return errors.New("timeout")
+		}
+		n.SetData(true)
+		return nil
+	}
+	node := NewNode("A", flakyLoader, NewNodeGroup())
+	loader := NewLoader(1, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	assert.Nil(t, loader.Load(context.Background(), node))
+	assert.Equal(t, 3, fetches)
+	assert.True(t, node.HasData())
+	assert.Equal(t, 0, loader.Failed())
+
+	var brokenLoader NodeFetcher = func(n *Node) error { return errors.New("timeout") }
+	assert.NotNil(t, loader.Load(context.Background(), NewNode("B", brokenLoader, NewNodeGroup())))
+	assert.Equal(t, 1, loader.Failed())
+	assert.Nil(t, loader.Missing("B"))
+}
+
+func TestLoaderNeverRefetchesPermanentlyMissingNodes(t *testing.T) {
+	fetches := 0
+	var missingLoader NodeFetcher = func(n *Node) error {
+		fetches++
+		return Permanent(errors.New("not found"))
+	}
+	group := NewNodeGroup()
+	node := NewNode("A", missingLoader, group)
+	loader := NewLoader(1, RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	assert.NotNil(t, loader.Load(context.Background(), node))
+	assert.NotNil(t, loader.Load(context.Background(), node))
+	assert.Equal(t, 1, fetches)
+	assert.True(t, IsPermanent(loader.Missing("A")))
+	assert.Nil(t, loader.Missing("B"))
+}
@@ -1,20 +1,19 @@
 package graph
 
 import (
+	"context"
 	"fmt"
-	"sort"
 	"sync"
-	"time"
 )
 
-const (
-	debug           = false
-	maxLoadAttempts = 3
-)
+const debug = false
 
 // NodeFetcher is a function that can lazily load Node data.
 type NodeFetcher func(*Node) error
 
+// ContextNodeFetcher is a function that can lazily load Node data, and gives up when the context is done.
+type ContextNodeFetcher func(context.Context, *Node) error
+
 var defaultNodeFetcher NodeFetcher = func(n *Node) error {
 	n.SetData(true)
 	return nil
@@ -22,43 +21,49 @@ var defaultNodeFetcher NodeFetcher = func(n *Node) error {
 
 // Node represents a graph node.
 type Node struct {
-	ID         string
-	data       interface{}
-	neighbours []*Node
-	load       NodeFetcher
-	group      *NodeGroup
-	lock       sync.Mutex
+	ID          string
+	data        interface{}
+	neighbours  []*Node
+	edgeData    map[string]interface{}
+	edgeWeights map[string]float64
+	load        NodeFetcher
+	loadCtx     ContextNodeFetcher
+	group       *NodeGroup
+	lock        sync.Mutex
 	//	paths      map[string][]Path
 }
 
 // NewNode constructs a new node with an ID and a lazy loader, and returns a pointer to the newly constructed Node.
 // Parameter 1: id - ID for the new Node.
-// Parameter 2: loader - NodeFetcher to lazy load the Node. Defaults to an empty NodeFetcher if not specified.
+// Parameter 2: loader - NodeFetcher or ContextNodeFetcher to lazy load the Node. Defaults to an empty NodeFetcher if not specified.
 // Parameter 3: group - NodeGroup that this Node should belong to. Defaults to a default NodeGroup if not specified.
 func NewNode(id string, otherArgs ...interface{}) *Node {
 	var loader NodeFetcher
+	var contextLoader ContextNodeFetcher
 	var group *NodeGroup
 
 	if len(otherArgs) > 0 && otherArgs[0] != nil {
-		loader = otherArgs[0].(NodeFetcher)
+		switch fetcher := otherArgs[0].(type) {
+		case ContextNodeFetcher:
+			contextLoader = fetcher
+		default:
+			loader = fetcher.(NodeFetcher)
+		}
 	}
 	if len(otherArgs) > 1 {
 		group = otherArgs[1].(*NodeGroup)
 	}
 
-	if loader == nil {
+	if loader == nil && contextLoader == nil {
 		loader = defaultNodeFetcher
 	}
 	if group == nil {
 		group = defaultNodeGroup
 	}
 
-	node, present := group.Get(id)
-	if present {
-		return node
-	}
-	node = &Node{ID: id, load: loader /*paths: make(map[string][]Path)*/}
-	group.Register(node)
+	node, _ := group.GetOrCreate(id, func() *Node {
+		return &Node{ID: id, load: loader, loadCtx: contextLoader /*paths: make(map[string][]Path)*/}
+	})
 	return node
 }
 
@@ -73,15 +78,69 @@ func (n *Node) Equal(other *Node) bool {
 	return n.ID == other.ID
 }
 
-// Connect bidirectionally connects two graph Nodes.
-func (n *Node) Connect(other *Node) {
+// Connect bidirectionally connects two graph Nodes in a thread-safe manner, so Nodes can be connected during a search.
+// Connecting two Nodes again merges the new metadata into the Edge's existing metadata if it is a Merger, and keeps the
+// lowest of the Edge's weights, so that the Edge ends up the same whichever order its connections are made in.
+// Parameter 1: data - Metadata to attach to the Edge between the two Nodes. Kept as is if the Edge already has some, unless it is a Merger.
+// Parameter 2: weight - float64 weight of the Edge between the two Nodes. Defaults to 1.
+func (n *Node) Connect(other *Node, args ...interface{}) {
+	var data interface{}
+	if len(args) > 0 {
+		data = args[0]
+	}
+	var weight *float64
+	if len(args) > 1 {
+		edgeWeight := args[1].(float64)
+		weight = &edgeWeight
+	}
+	n.addNeighbour(other, data, weight)
+	other.addNeighbour(n, data, weight)
+}
+
+// addNeighbour connects the current Node to another in one direction only.
+// Only one Node is locked at a time, so that two Nodes connecting to each other concurrently can't deadlock.
+func (n *Node) addNeighbour(other *Node, data interface{}, weight *float64) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
 	n.neighbours = appendNodeIfMissing(n.neighbours, other)
-	other.neighbours = appendNodeIfMissing(other.neighbours, n)
+	if data != nil {
+		n.mergeEdgeData(other, data)
+	}
+	if weight != nil {
+		n.mergeEdgeWeight(other, *weight)
+	}
+}
+
+// Neighbours returns a snapshot of the Node's immediate neighbours in a thread-safe manner.
+// Neighbours connected after the snapshot is taken are not included in it.
+func (n *Node) Neighbours() []*Node {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return append([]*Node{}, n.neighbours...)
+}
+
+// NewNeighbour connects the current Node to a Node with the given ID, and returns it.
+// The neighbour belongs to the same NodeGroup as the current Node, and lazy loads with the same loader.
+// Parameter 1: data - Metadata to attach to the Edge between the two Nodes, unless the Edge already has some.
+// Parameter 2: weight - float64 weight of the Edge between the two Nodes, unless the Edge already has one. Defaults to 1.
+func (n *Node) NewNeighbour(id string, args ...interface{}) *Node {
+	var loader interface{} = n.load
+	if n.loadCtx != nil {
+		loader = n.loadCtx
+	}
+	neighbour := NewNode(id, loader, n.group)
+	n.Connect(neighbour, args...)
+	return neighbour
+}
+
+// Group returns the NodeGroup that the current Node belongs to.
+func (n *Node) Group() *NodeGroup {
+	return n.group
 }
 
 // IsNeighbour returns true if the given node is an immediate neighbour of the current node, false otherwise.
 func (n *Node) IsNeighbour(other *Node) bool {
-	for _, neighbour := range n.neighbours {
+	for _, neighbour := range n.Neighbours() {
 		if other.Equal(neighbour) {
 			return true
 		}
@@ -96,6 +155,13 @@ func (n *Node) SetData(data interface{}) {
 	n.lock.Unlock()
 }
 
+// Data returns Node data in a thread-safe manner. Returns nil if the Node hasn't been loaded yet.
+func (n *Node) Data() interface{} {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.data
+}
+
 // HasData checks for presence of Node data in a thread-safe manner.
 func (n *Node) HasData() bool {
 	result := false
@@ -105,97 +171,29 @@ func (n *Node) HasData() bool {
 	return result
 }
 
-// PathsTo computes all possible paths from the current node to the target node.
+// PathsTo computes all possible paths from the current node to the target node. See AllShortestPathsTo for only the shortest ones.
 // It returns an empty slice when no paths are available.
+// Parameter 1: stopAtFirstPath - When true, performs a breadth first search and returns only a single shortest path. Defaults to false.
 func (n *Node) PathsTo(target *Node, args ...interface{}) []Path {
-	stopAtFirstPath := false
-	if len(args) > 0 {
-		stopAtFirstPath = args[0].(bool)
-	}
-
-	chanResults := make(chan []Path)
-	go n.pathsTo(target, 0, Path{n, target}.String(), stopAtFirstPath, Path{}, chanResults)
-	paths := <-chanResults
-	sort.Stable(byPathLength(paths))
+	paths, _ := n.PathsToContext(context.Background(), target, args...)
 	return paths
 }
 
-func (n *Node) pathsTo(target *Node, depth int, pathID string, stopAtFirstPath bool, currentPath Path, chanResults chan []Path) {
-	if debug {
-		tabs(depth)
-		fmt.Printf("pathsTo(%v, %v, %v, >>%v<<)\n", n, target, depth, currentPath)
-	}
-
-	if stopAtFirstPath && n.group.pathsFound[pathID] {
-		chanResults <- []Path{}
-		return
-	}
-	// Lazy load Node
-	loadAttempt := 0
-	for !n.HasData() {
-		if debug {
-			tabs(depth)
-			fmt.Printf("Loading %v. Attempt %v\n", n.ID, loadAttempt)
-		}
-		err := n.load(n)
-		// Retry loading node after a pause if there was an error while loading
-		if err != nil {
-			if loadAttempt > maxLoadAttempts {
-				if debug {
-					tabs(depth)
-					fmt.Printf(">>>>>>>>>>>>>>>>> Failed to load %v. Bailing out.\n", n.ID)
-				}
-				chanResults <- []Path{}
-				return
-			}
-			loadAttempt++
-			time.Sleep(1 * time.Second)
-		}
-	}
-
-	// Skip if this node has already been visited in the current run
-	n.group.lock.Lock()
-	loop := currentPath.Contains(n)
-	n.group.lock.Unlock()
-	if loop {
-		chanResults <- []Path{}
-		return
-	}
-	n.group.lock.Lock()
-	currentPath = append(currentPath, n)
-	n.group.lock.Unlock()
-
-	if n.Equal(target) {
-		if debug {
-			tabs(depth)
-			fmt.Printf("$$$$$$$$$$$$$ [%v] %v -> %v Found: %v\n", pathID, n, target, currentPath)
-		}
-		n.group.lock.Lock()
-		n.group.pathsFound[pathID] = true
-		n.group.lock.Unlock()
-		chanResults <- []Path{currentPath}
-		//		n.paths[target.ID] = append(n.paths[target.ID], currentPath)
-		return
-	}
-
-	// Search for paths from neighbours
-	chanNeighbourResults := make(chan []Path)
-	if depth < n.group.maxRecursionDepth {
-		for _, neighbour := range n.neighbours {
-			go neighbour.pathsTo(target, depth+1, pathID, stopAtFirstPath, currentPath, chanNeighbourResults)
-		}
-	}
+// PathsToContext is PathsTo, but stops loading Nodes and returns as soon as the context is done.
+// It returns the paths found so far along with the context's error when the search could not be completed.
+// Each call is an independent Search, so repeated and concurrent queries over the same NodeGroup don't affect each other.
+func (n *Node) PathsToContext(ctx context.Context, target *Node, args ...interface{}) ([]Path, error) {
+	search := NewSearch(ctx, n.group)
+	defer search.Cancel()
+	return search.PathsTo(n, target, args...)
+}
 
-	results := []Path{}
-	for i := 0; i < len(n.neighbours); i++ {
-		neighbourPaths := <-chanNeighbourResults
-		results = append(results, neighbourPaths...)
+// fetch invokes the Node's fetcher once, passing the context along to it if it accepts one.
+func (n *Node) fetch(ctx context.Context) error {
+	if n.loadCtx != nil {
+		return n.loadCtx(ctx, n)
 	}
-	//HACK
-	results = deDuplicatePaths(results)
-	chanResults <- results
-	//	n.paths[target.ID] = append(n.paths[target.ID], allPaths...)
-	return
+	return n.load(n)
 }
 
 func appendNodeIfMissing(nodes []*Node, nodeToAppend *Node) []*Node {
@@ -11,25 +11,37 @@ var defaultNodeGroup = NewNodeGroup()
 type NodeGroup struct {
 	nodes             map[string]*Node
 	maxRecursionDepth int
-	pathsFound        map[string]bool
+	loader            *Loader
 	lock              sync.Mutex
 }
 
 // NewNodeGroup creates a new NodeGroup
 // Parameter 1: maxRecursionDepth. Defaults to 6.
+// Parameter 2: concurrency - Maximum number of Nodes to load concurrently. Defaults to 16.
 func NewNodeGroup(args ...interface{}) *NodeGroup {
 	maxRecursionDepth := 6
 	if len(args) > 0 {
 		maxRecursionDepth = args[0].(int)
 	}
+	loader := NewLoader()
+	if len(args) > 1 {
+		loader = NewLoader(args[1].(int))
+	}
 	return &NodeGroup{nodes: make(map[string]*Node),
-		pathsFound:        make(map[string]bool),
-		maxRecursionDepth: maxRecursionDepth}
+		maxRecursionDepth: maxRecursionDepth,
+		loader:            loader}
+}
+
+// Loader returns the Loader that lazily loads Nodes searched from this NodeGroup.
+func (g *NodeGroup) Loader() *Loader {
+	return g.loader
 }
 
 // Register registers a Node with the current NodeGroup by it's ID
 func (g *NodeGroup) Register(node *Node) error {
-	if _, exists := g.Get(node.ID); exists {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if _, exists := g.nodes[node.ID]; exists {
 		return errors.New("Another node has already been registered with the same ID")
 	}
 	g.nodes[node.ID] = node
@@ -40,6 +52,24 @@ func (g *NodeGroup) Register(node *Node) error {
 // Get finds and returns an existing Node in the current NodeGroup matching the given ID
 // Returns Node, true if found. Returns nil, false if not found.
 func (g *NodeGroup) Get(id string) (*Node, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
 	node, present := g.nodes[id]
 	return node, present
 }
+
+// GetOrCreate atomically finds the Node matching the given ID, or creates and registers one if there isn't any.
+// create is only called when the Node doesn't exist yet, while the NodeGroup is locked, so it must not use the NodeGroup.
+// Returns the Node, and true if it was created by this call.
+func (g *NodeGroup) GetOrCreate(id string, create func() *Node) (*Node, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if node, present := g.nodes[id]; present {
+		return node, false
+	}
+	node := create()
+	node.ID = id
+	node.group = g
+	g.nodes[id] = node
+	return node, true
+}
@@ -1,7 +1,9 @@
 package graph
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"sync"
 	"testing"
 )
 
@@ -33,3 +35,73 @@ func TestGetNode(t *testing.T) {
 		t.Errorf("Expected original and found nodes to be identical, but they weren't.")
 	}
 }
+
+func TestGetOrCreateOnlyCreatesMissingNodes(t *testing.T) {
+	group := NewNodeGroup()
+	node, created := group.GetOrCreate("one", func() *Node { return &Node{} })
+	assert.True(t, created)
+	assert.Equal(t, "one", node.ID)
+	assert.Equal(t, group, node.group)
+
+	again, created := group.GetOrCreate("one", func() *Node {
+		t.Errorf("Expected an existing node to be reused, but a new one was created.")
+		return &Node{}
+	})
+	assert.False(t, created)
+	if node != again {
+		t.Errorf("Expected the same node to be returned for the same ID, but it wasn't.")
+	}
+}
+
+func TestConcurrentNewNodesShareInstances(t *testing.T) {
+	group := NewNodeGroup()
+	const goroutines, ids = 64, 20
+	nodes := make([][]*Node, goroutines)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < ids; i++ {
+				nodes[g] = append(nodes[g], NewNode(fmt.Sprintf("node-%v", i), nil, group))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.Equal(t, ids, len(group.nodes))
+	for g := 1; g < goroutines; g++ {
+		for i := 0; i < ids; i++ {
+			if nodes[g][i] != nodes[0][i] {
+				t.Errorf("Expected a single instance of %v, but found several.", nodes[0][i])
+			}
+		}
+	}
+}
+
+func TestConcurrentRegistrationAcceptsEachIDOnce(t *testing.T) {
+	group := NewNodeGroup()
+	const goroutines = 64
+	errors := make(chan error, goroutines)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errors <- group.Register(&Node{ID: "contended"})
+			group.Get("contended")
+		}()
+	}
+	wg.Wait()
+	close(errors)
+
+	registered := 0
+	for err := range errors {
+		if err == nil {
+			registered++
+		}
+	}
+	assert.Equal(t, 1, registered)
+}
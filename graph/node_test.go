@@ -1,30 +1,39 @@
 package graph
 
 import (
+	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
 func TestNodeConstruction(t *testing.T) {
 	node := NewNode("one")
-	assert.Equal(t, node.load, defaultNodeFetcher)
+	assertSameFunc(t, defaultNodeFetcher, node.load)
 	assert.Equal(t, node.group, defaultNodeGroup)
 	//	assert.NotNil(t, node.paths)
 
 	var nodeFetcher NodeFetcher = func(n *Node) error { return nil }
 	node = NewNode("two", nodeFetcher)
-	assert.Equal(t, node.load, nodeFetcher)
+	assertSameFunc(t, nodeFetcher, node.load)
 	assert.Equal(t, node.group, defaultNodeGroup)
 	//	assert.NotNil(t, node.paths)
 
 	nodeGroup := NewNodeGroup()
 	node = NewNode("two", nil, nodeGroup)
-	assert.Equal(t, node.load, defaultNodeFetcher)
+	assertSameFunc(t, defaultNodeFetcher, node.load)
 	assert.Equal(t, node.group, nodeGroup)
 	//	assert.NotNil(t, node.paths)
 }
 
+// assertSameFunc asserts that two functions are the same, since functions can't be compared with ==
+func assertSameFunc(t *testing.T, expected, actual interface{}) {
+	if reflect.ValueOf(expected).Pointer() != reflect.ValueOf(actual).Pointer() {
+		t.Errorf("Expected function %v, got %v", reflect.ValueOf(expected), reflect.ValueOf(actual))
+	}
+}
+
 func TestNewNodeReturnsExistingNodesMatchingIDFromTheNodeGroup(t *testing.T) {
 	nodeOne := NewNode("one")
 	nodeTwo := NewNode("two")
@@ -276,3 +285,155 @@ func TestPathCaching(t *testing.T) {
 	}
 }
 */
+
+func TestNodeLazyLoadingWithContextNodeFetcher(t *testing.T) {
+	group := NewNodeGroup()
+	b := NewNode("B", nil, group)
+
+	var contextLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		n.SetData(ctx.Value(contextKey("marker")))
+		n.Connect(b)
+		return nil
+	}
+	a := NewNode("A", contextLoader, group)
+
+	ctx := context.WithValue(context.Background(), contextKey("marker"), "loaded")
+	aToB, err := a.PathsToContext(ctx, b, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(aToB))
+	assert.Equal(t, "loaded", a.data)
+}
+
+func TestPathsToContextStopsWhenContextIsDone(t *testing.T) {
+	group := NewNodeGroup()
+	loadsStarted := make(chan bool, 10)
+	var blockingLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		loadsStarted <- true
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	a := NewNode("A", blockingLoader, group)
+	b := NewNode("B", blockingLoader, group)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-loadsStarted
+		cancel()
+	}()
+	paths, err := a.PathsToContext(ctx, b, true)
+	assert.Equal(t, 0, len(paths))
+	assert.Equal(t, context.Canceled, err)
+
+	paths, err = a.PathsToContext(ctx, b)
+	assert.Equal(t, 0, len(paths))
+	assert.Equal(t, context.Canceled, err)
+}
+
+type contextKey string
+
+func TestConnectLabelsEdgesWithData(t *testing.T) {
+	a := &Node{ID: "A"}
+	b := &Node{ID: "B"}
+	c := &Node{ID: "C"}
+
+	a.Connect(b, "A-B")
+	a.Connect(c)
+	b.Connect(a, "B-A")
+
+	edge, connected := a.EdgeTo(b)
+	assert.True(t, connected)
+	assert.Equal(t, a, edge.From)
+	assert.Equal(t, b, edge.To)
+	assert.Equal(t, "A-B", edge.Data)
+
+	edge, connected = b.EdgeTo(a)
+	assert.True(t, connected)
+	assert.Equal(t, "A-B", edge.Data)
+
+	edge, connected = a.EdgeTo(c)
+	assert.True(t, connected)
+	assert.Nil(t, edge.Data)
+
+	_, connected = b.EdgeTo(c)
+	assert.False(t, connected)
+}
+
+// labels is Edge metadata that merges by concatenating labels.
+type labels string
+
+func (l labels) Merge(other interface{}) interface{} {
+	return l + "+" + other.(labels)
+}
+
+func TestConnectingAgainMergesEdges(t *testing.T) {
+	a := &Node{ID: "A"}
+	b := &Node{ID: "B"}
+
+	a.Connect(b, labels("first"), 7.0)
+	b.Connect(a, labels("second"), 2.5)
+	a.Connect(b, labels("third"), 4.0)
+
+	for _, edge := range []Edge{a.Edges()[0], b.Edges()[0]} {
+		assert.Equal(t, labels("first+second+third"), edge.Data)
+		assert.Equal(t, 2.5, edge.Weight)
+	}
+}
+
+func TestNewNeighbourSharesGroupAndLoader(t *testing.T) {
+	group := NewNodeGroup()
+	var loader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		n.SetData(n.ID)
+		return nil
+	}
+	node := NewNode("parent", loader, group)
+
+	neighbour := node.NewNeighbour("child", "an edge")
+	assert.Equal(t, group, neighbour.Group())
+	assertSameFunc(t, loader, neighbour.loadCtx)
+	assert.True(t, node.IsNeighbour(neighbour))
+	edge, _ := node.EdgeTo(neighbour)
+	assert.Equal(t, "an edge", edge.Data)
+
+	existing, _ := group.Get("child")
+	assert.True(t, existing == neighbour)
+}
+
+func TestNeighboursReturnsASnapshot(t *testing.T) {
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	a.Connect(b)
+
+	neighbours := a.Neighbours()
+	neighbours[0] = nil
+	a.Connect(NewNode("C", nil, group))
+	assert.Equal(t, 1, len(neighbours))
+	assert.Equal(t, "[B C]", fmt.Sprint(a.Neighbours()))
+}
+
+func TestConnectingDuringASearchIsSafe(t *testing.T) {
+	group := NewNodeGroup(20)
+	hub := NewNode("hub", nil, group)
+	target := NewNode("target", nil, group)
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 200; i++ {
+			spoke := NewNode(fmt.Sprintf("spoke-%v", i), nil, group)
+			hub.Connect(spoke, i)
+			spoke.Connect(hub)
+		}
+		hub.Connect(target)
+		close(done)
+	}()
+	for i := 0; i < 20; i++ {
+		hub.BidirectionalPathsTo(target)
+		hub.PathsTo(target, true)
+		hub.EdgeTo(target)
+	}
+	<-done
+
+	paths := hub.PathsTo(target, true)
+	assert.Equal(t, 1, len(paths))
+	assert.Equal(t, 201, len(hub.Neighbours()))
+}
@@ -43,6 +43,33 @@ func (p Path) Contains(node *Node) bool {
 	return false
 }
 
+// Edge returns the Edge between the i-th and the (i+1)-th Nodes in the path.
+func (p Path) Edge(i int) Edge {
+	edge, _ := p[i].EdgeTo(p[i+1])
+	if edge.To == nil {
+		edge = Edge{From: p[i], To: p[i+1], Weight: defaultEdgeWeight}
+	}
+	return edge
+}
+
+// Edges returns the Edges between every pair of consecutive Nodes in the path.
+func (p Path) Edges() []Edge {
+	edges := []Edge{}
+	for i := 0; i+1 < len(p); i++ {
+		edges = append(edges, p.Edge(i))
+	}
+	return edges
+}
+
+// Weight returns the total weight of the Edges along the path.
+func (p Path) Weight() float64 {
+	weight := 0.0
+	for _, edge := range p.Edges() {
+		weight += edge.Weight
+	}
+	return weight
+}
+
 type byPathLength []Path
 
 func (a byPathLength) Len() int      { return len(a) }
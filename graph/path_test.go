@@ -71,3 +71,26 @@ func TestPathContainsNode(t *testing.T) {
 		t.Errorf("%v should not contain %v, but it didn't", path, d)
 	}
 }
+
+func TestPathEdges(t *testing.T) {
+	a := &Node{ID: "A"}
+	b := &Node{ID: "B"}
+	c := &Node{ID: "C"}
+	a.Connect(b, "A-B")
+	b.Connect(c, "B-C")
+	path := Path{a, b, c}
+
+	edges := path.Edges()
+	if len(edges) != 2 {
+		t.Fatalf("Found %d edges in %v. Expected exactly two edges.", len(edges), path)
+	}
+	if edges[0].String() != "A -> B" || edges[0].Data != "A-B" {
+		t.Errorf("First edge of %v was %v (%v). Expected A -> B (A-B)", path, edges[0], edges[0].Data)
+	}
+	if edges[1].String() != "B -> C" || edges[1].Data != "B-C" {
+		t.Errorf("Second edge of %v was %v (%v). Expected B -> C (B-C)", path, edges[1], edges[1].Data)
+	}
+	if len(Path{a}.Edges()) != 0 {
+		t.Errorf("Expected a single node path to have no edges")
+	}
+}
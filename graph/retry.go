@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// DefaultRetryPolicy is the RetryPolicy used by Loaders unless another one is specified.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+
+// RetryPolicy decides how often, and after how long, failed Node loads are retried.
+// Transient failures are retried with exponential backoff and jitter. Permanent failures are never retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// ShouldRetry returns true if a load that failed with the given error on the given (zero based) attempt should be retried.
+func (p RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	return !IsPermanent(err) && attempt+1 < p.MaxAttempts
+}
+
+// Delay returns how long to wait before retrying after the given (zero based) attempt.
+// The delay doubles with every attempt up to MaxDelay, and a random half of it is shaved off
+// so that loads failing together don't all retry together.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// permanentError wraps an error that will not go away by retrying.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error returned by a NodeFetcher as permanent, so that the load is not retried.
+// For example, a Node that doesn't exist will never load, no matter how many times we try.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if the error, or any error it wraps, was marked as permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
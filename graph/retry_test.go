@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBacksOffExponentiallyWithJitter(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, maxDelay := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		maxDelay *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			delay := policy.Delay(attempt)
+			assert.True(t, delay >= maxDelay/2, "Delay %v for attempt %v was shorter than %v", delay, attempt, maxDelay/2)
+			assert.True(t, delay <= maxDelay, "Delay %v for attempt %v was longer than %v", delay, attempt, maxDelay)
+		}
+	}
+}
+
+func TestRetryPolicyRetriesOnlyTransientErrors(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}
+	transient := errors.New("timeout")
+	permanent := Permanent(errors.New("not found"))
+
+	assert.True(t, policy.ShouldRetry(0, transient))
+	assert.True(t, policy.ShouldRetry(1, transient))
+	assert.False(t, policy.ShouldRetry(2, transient))
+	assert.False(t, policy.ShouldRetry(0, permanent))
+}
+
+func TestPermanentErrors(t *testing.T) {
+	err := errors.New("not found")
+	assert.Nil(t, Permanent(nil))
+	assert.False(t, IsPermanent(err))
+	assert.True(t, IsPermanent(Permanent(err)))
+	assert.True(t, IsPermanent(fmt.Errorf("loading A: %w", Permanent(err))))
+	assert.Equal(t, "not found", Permanent(err).Error())
+	assert.True(t, errors.Is(Permanent(err), err))
+}
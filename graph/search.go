@@ -0,0 +1,220 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Search is a single query over a NodeGroup, and holds all the state of that query.
+// Many Searches can run concurrently over one NodeGroup, sharing its Nodes and everything already loaded into them,
+// without seeing each other's visited Nodes or results.
+type Search struct {
+	group    *NodeGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	maxDepth int
+	filter   Filter
+	avoided  map[string]bool
+	visited  map[string]bool
+	found    bool
+	stats    SearchStats
+	lock     sync.Mutex
+}
+
+// Filter decides whether a Search may traverse an Edge, given its metadata and the Nodes at either end of it.
+// The Node the Edge leads to may not have been loaded yet, so its data may be nil.
+type Filter func(edge Edge) bool
+
+// SearchStats describes the work done by a Search.
+type SearchStats struct {
+	NodesVisited  int
+	NodesExpanded int
+	PathsFound    int
+}
+
+// NewSearch creates a Search over a NodeGroup, which stops loading Nodes once the context is done or the Search is cancelled.
+// The Search holds on to resources of the context until it is cancelled, so callers must Cancel it once they are done with it.
+// Parameter 1: maxDepth - Maximum number of hops to search. Defaults to the NodeGroup's maximum recursion depth.
+func NewSearch(ctx context.Context, group *NodeGroup, args ...interface{}) *Search {
+	maxDepth := group.maxRecursionDepth
+	if len(args) > 0 {
+		maxDepth = args[0].(int)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Search{group: group, ctx: ctx, cancel: cancel, maxDepth: maxDepth,
+		avoided: make(map[string]bool),
+		visited: make(map[string]bool)}
+}
+
+// SetFilter restricts the Search to Edges that the filter allows, in the direction of the paths being searched for.
+// Must be called before the Search starts.
+func (s *Search) SetFilter(filter Filter) {
+	s.filter = filter
+}
+
+// Avoid excludes the given Nodes from every path the Search finds. Avoiding the source or target of a path means it
+// can't be found. Must be called before the Search starts.
+func (s *Search) Avoid(nodes ...*Node) {
+	for _, node := range nodes {
+		s.avoided[node.ID] = true
+	}
+}
+
+// Cancel stops the Search. Any query in progress returns context.Canceled.
+func (s *Search) Cancel() {
+	s.cancel()
+}
+
+// Err returns the reason the Search was stopped, or nil if it hasn't been.
+func (s *Search) Err() error {
+	return s.ctx.Err()
+}
+
+// Found returns true if the Search has found at least one path.
+func (s *Search) Found() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.found
+}
+
+// Stats returns the work done by the Search so far in a thread-safe manner.
+func (s *Search) Stats() SearchStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.stats
+}
+
+// PathsTo computes all possible paths from the source node to the target node.
+// It returns an empty slice when no paths are available, along with the Search's error if it was stopped.
+// Parameter 1: stopAtFirstPath - When true, performs a breadth first search and returns only a single shortest path. Defaults to false.
+func (s *Search) PathsTo(source, target *Node, args ...interface{}) ([]Path, error) {
+	stopAtFirstPath := false
+	if len(args) > 0 {
+		stopAtFirstPath = args[0].(bool)
+	}
+
+	if stopAtFirstPath {
+		path, err := s.ShortestPath(source, target)
+		if path == nil {
+			return []Path{}, err
+		}
+		return []Path{path}, nil
+	}
+
+	chanResults := make(chan []Path)
+	go s.pathsTo(source, target, 0, Path{}, chanResults)
+	paths := <-chanResults
+	sort.Stable(byPathLength(paths))
+	return paths, s.Err()
+}
+
+// allows returns true if the Search may traverse the given Edge.
+func (s *Search) allows(edge Edge) bool {
+	if s.avoided[edge.From.ID] || s.avoided[edge.To.ID] {
+		return false
+	}
+	return s.filter == nil || s.filter(edge)
+}
+
+// traverses returns true if the Search may traverse the Edge from one Node to another.
+func (s *Search) traverses(from, to *Node) bool {
+	if s.avoided[from.ID] || s.avoided[to.ID] {
+		return false
+	}
+	if s.filter == nil {
+		return true
+	}
+	edge, connected := from.EdgeTo(to)
+	return connected && s.filter(edge)
+}
+
+// visit records that the Search has reached a node.
+func (s *Search) visit(node *Node) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.visited[node.ID] {
+		s.visited[node.ID] = true
+		s.stats.NodesVisited++
+	}
+}
+
+// expanded records that the Search has explored the neighbours of the given number of nodes.
+func (s *Search) expanded(count int) {
+	s.lock.Lock()
+	s.stats.NodesExpanded += count
+	s.lock.Unlock()
+}
+
+// foundPaths records that the Search has found the given number of paths.
+func (s *Search) foundPaths(count int) {
+	s.lock.Lock()
+	s.stats.PathsFound += count
+	s.found = s.found || count > 0
+	s.lock.Unlock()
+}
+
+func (s *Search) pathsTo(n *Node, target *Node, depth int, currentPath Path, chanResults chan []Path) {
+	if debug {
+		tabs(depth)
+		fmt.Printf("pathsTo(%v, %v, %v, >>%v<<)\n", n, target, depth, currentPath)
+	}
+
+	if s.Err() != nil {
+		chanResults <- []Path{}
+		return
+	}
+	s.visit(n)
+	// Lazy load Node
+	if err := s.group.loader.Load(s.ctx, n); err != nil {
+		if debug {
+			tabs(depth)
+			fmt.Printf(">>>>>>>>>>>>>>>>> Failed to load %v. Bailing out.\n", n.ID)
+		}
+		chanResults <- []Path{}
+		return
+	}
+
+	// Skip if this node has already been visited along the current path
+	if currentPath.Contains(n) {
+		chanResults <- []Path{}
+		return
+	}
+	// Copy the path, so that concurrent searches through sibling neighbours don't overwrite each other's paths
+	currentPath = append(append(Path{}, currentPath...), n)
+
+	if n.Equal(target) {
+		if debug {
+			tabs(depth)
+			fmt.Printf("$$$$$$$$$$$$$ %v -> %v Found: %v\n", currentPath[0], target, currentPath)
+		}
+		s.foundPaths(1)
+		chanResults <- []Path{currentPath}
+		return
+	}
+
+	// Search for paths from neighbours
+	chanNeighbourResults := make(chan []Path)
+	neighbours := []*Node{}
+	if depth < s.maxDepth {
+		for _, neighbour := range n.Neighbours() {
+			if s.traverses(n, neighbour) {
+				neighbours = append(neighbours, neighbour)
+			}
+		}
+		s.expanded(1)
+	}
+	for _, neighbour := range neighbours {
+		go s.pathsTo(neighbour, target, depth+1, currentPath, chanNeighbourResults)
+	}
+
+	results := []Path{}
+	for i := 0; i < len(neighbours); i++ {
+		neighbourPaths := <-chanNeighbourResults
+		results = append(results, neighbourPaths...)
+	}
+	//HACK
+	results = deDuplicatePaths(results)
+	chanResults <- results
+}
@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// diamond creates A--B--D and A--C--D in a new NodeGroup.
+func diamond() (*NodeGroup, *Node, *Node) {
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+	a.Connect(b)
+	a.Connect(c)
+	b.Connect(d)
+	c.Connect(d)
+	return group, a, d
+}
+
+func TestRepeatedQueriesOverOneGroupFindTheSamePaths(t *testing.T) {
+	_, a, d := diamond()
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 2, len(a.PathsTo(d)))
+		assert.Equal(t, 1, len(a.PathsTo(d, true)))
+		assert.Equal(t, 1, len(a.BidirectionalPathsTo(d)))
+	}
+}
+
+func TestConcurrentSearchesOverOneGroupAreIndependent(t *testing.T) {
+	group, a, d := diamond()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			search := NewSearch(context.Background(), group)
+			paths, err := search.PathsTo(a, d)
+			assert.Nil(t, err)
+			assert.Equal(t, "[A -> B -> D A -> C -> D]", fmt.Sprint(sortedPaths(paths)))
+			assert.True(t, search.Found())
+			assert.Equal(t, 2, search.Stats().PathsFound)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSearchStats(t *testing.T) {
+	group, a, d := diamond()
+
+	search := NewSearch(context.Background(), group)
+	path, err := search.ShortestPath(a, d)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(path))
+	assert.Equal(t, SearchStats{NodesVisited: 4, NodesExpanded: 3, PathsFound: 1}, search.Stats())
+
+	search = NewSearch(context.Background(), group, 1)
+	path, _ = search.BidirectionalPath(a, NewNode("unreachable", nil, group))
+	assert.Nil(t, path)
+	assert.False(t, search.Found())
+}
+
+func TestCancelledSearchesStop(t *testing.T) {
+	group, a, d := diamond()
+
+	search := NewSearch(context.Background(), group)
+	search.Cancel()
+	path, err := search.ShortestPath(a, d)
+	assert.Nil(t, path)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, context.Canceled, search.Err())
+
+	paths, err := search.PathsTo(a, d)
+	assert.Equal(t, 0, len(paths))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func sortedPaths(paths []Path) []string {
+	result := []string{}
+	for _, path := range paths {
+		result = append(result, path.String())
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// AllShortestPathsTo computes every minimum-length path from the current node to the target node.
+// It returns an empty slice when no path is available within the NodeGroup's maximum recursion depth.
+// Parameter 1: limit - Maximum number of paths to return. Returns all of them if 0 or less, which is the default.
+func (n *Node) AllShortestPathsTo(target *Node, args ...interface{}) []Path {
+	paths, _ := n.AllShortestPathsToContext(context.Background(), target, args...)
+	return paths
+}
+
+// AllShortestPathsToContext is AllShortestPathsTo, but stops loading Nodes and returns as soon as the context is done.
+// It returns an empty slice along with the context's error when the search could not be completed.
+func (n *Node) AllShortestPathsToContext(ctx context.Context, target *Node, args ...interface{}) ([]Path, error) {
+	search := NewSearch(ctx, n.group)
+	defer search.Cancel()
+	return search.AllShortestPaths(n, target, args...)
+}
+
+// AllShortestPaths computes every minimum-length path from the source node to the target node.
+// A breadth first search records every predecessor of each node on the level before it, which forms a DAG of the
+// shortest paths to each node. Once the level containing the target has been explored completely, the paths are
+// enumerated from the target back to the source. The total number of shortest paths is recorded in the Search's stats
+// as PathsFound, even when fewer of them are returned.
+// Parameter 1: limit - Maximum number of paths to return. Returns all of them if 0 or less, which is the default.
+func (s *Search) AllShortestPaths(source, target *Node, args ...interface{}) ([]Path, error) {
+	limit := 0
+	if len(args) > 0 {
+		limit = args[0].(int)
+	}
+
+	s.visit(source)
+	if source.Equal(target) {
+		s.foundPaths(1)
+		return []Path{Path{source}}, nil
+	}
+
+	depths := map[string]int{source.ID: 0}
+	predecessors := make(map[string][]*Node)
+	frontier := []*Node{source}
+	for depth := 0; depth < s.maxDepth && len(frontier) > 0; depth++ {
+		if debug {
+			fmt.Printf("All shortest paths depth %v: %v node(s) in frontier\n", depth, len(frontier))
+		}
+		s.group.loader.LoadAll(s.ctx, frontier)
+		if err := s.Err(); err != nil {
+			return []Path{}, err
+		}
+		s.expanded(len(frontier))
+
+		next := []*Node{}
+		for _, node := range frontier {
+			for _, neighbour := range node.Neighbours() {
+				if !s.traverses(node, neighbour) {
+					continue
+				}
+				neighbourDepth, seen := depths[neighbour.ID]
+				if !seen {
+					depths[neighbour.ID] = depth + 1
+					s.visit(neighbour)
+					next = append(next, neighbour)
+				} else if neighbourDepth != depth+1 {
+					continue
+				}
+				predecessors[neighbour.ID] = append(predecessors[neighbour.ID], node)
+			}
+		}
+
+		if _, reached := depths[target.ID]; reached {
+			// Neighbours are connected concurrently while loading, so order predecessors to enumerate paths deterministically
+			for _, nodes := range predecessors {
+				sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+			}
+			s.foundPaths(countPaths(predecessors, target, make(map[string]int)))
+			return enumeratePaths(predecessors, target, limit), nil
+		}
+		frontier = next
+	}
+	return []Path{}, nil
+}
+
+// countPaths counts the paths leading up to a node through a predecessor DAG, without enumerating them.
+func countPaths(predecessors map[string][]*Node, node *Node, counts map[string]int) int {
+	if count, counted := counts[node.ID]; counted {
+		return count
+	}
+	count := 0
+	for _, predecessor := range predecessors[node.ID] {
+		count += countPaths(predecessors, predecessor, counts)
+	}
+	if len(predecessors[node.ID]) == 0 {
+		count = 1
+	}
+	counts[node.ID] = count
+	return count
+}
+
+// enumeratePaths lists up to limit paths leading up to a node through a predecessor DAG, or all of them if limit is 0 or less.
+func enumeratePaths(predecessors map[string][]*Node, node *Node, limit int) []Path {
+	paths := []Path{}
+	var walk func(node *Node, suffix Path)
+	walk = func(node *Node, suffix Path) {
+		if limit > 0 && len(paths) >= limit {
+			return
+		}
+		suffix = append(Path{node}, suffix...)
+		if len(predecessors[node.ID]) == 0 {
+			paths = append(paths, suffix)
+			return
+		}
+		for _, predecessor := range predecessors[node.ID] {
+			walk(predecessor, suffix)
+		}
+	}
+	walk(node, Path{})
+	return paths
+}
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAllShortestPathsComputation(t *testing.T) {
+	/*
+	     B---E
+	    / \ / \
+	   A   X   G---H
+	    \ / \ /
+	     C---F
+	      \
+	       D
+	*/
+	group := NewNodeGroup()
+	nodes := make(map[string]*Node)
+	for _, id := range []string{"A", "B", "C", "D", "E", "F", "G", "H", "X"} {
+		nodes[id] = NewNode(id, nil, group)
+	}
+	for _, edge := range []string{"AB", "AC", "BE", "BX", "CX", "CF", "CD", "EX", "FX", "EG", "FG", "GH"} {
+		nodes[edge[:1]].Connect(nodes[edge[1:]])
+	}
+
+	paths := nodes["A"].AllShortestPathsTo(nodes["G"])
+	assert.Equal(t, "[A -> B -> E -> G A -> C -> F -> G]", fmt.Sprint(paths))
+
+	paths = nodes["A"].AllShortestPathsTo(nodes["X"])
+	assert.Equal(t, "[A -> B -> X A -> C -> X]", fmt.Sprint(paths))
+
+	search := NewSearch(context.Background(), group)
+	paths, err := search.AllShortestPaths(nodes["A"], nodes["H"], 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "[A -> B -> E -> G -> H]", fmt.Sprint(paths))
+	assert.Equal(t, 2, search.Stats().PathsFound)
+
+	assert.Equal(t, "[A]", fmt.Sprint(nodes["A"].AllShortestPathsTo(nodes["A"])))
+	assert.Equal(t, 0, len(nodes["A"].AllShortestPathsTo(NewNode("unconnected", nil, group))))
+}
+
+func TestAllShortestPathsCountsWithoutEnumerating(t *testing.T) {
+	// A ladder of 20 diamonds has 2^20 shortest paths from end to end
+	group := NewNodeGroup(40)
+	previous := NewNode("start", nil, group)
+	start := previous
+	for i := 0; i < 20; i++ {
+		top := NewNode(fmt.Sprintf("top-%v", i), nil, group)
+		bottom := NewNode(fmt.Sprintf("bottom-%v", i), nil, group)
+		next := NewNode(fmt.Sprintf("join-%v", i), nil, group)
+		previous.Connect(top)
+		previous.Connect(bottom)
+		top.Connect(next)
+		bottom.Connect(next)
+		previous = next
+	}
+
+	search := NewSearch(context.Background(), group)
+	paths, _ := search.AllShortestPaths(start, previous, 3)
+	assert.Equal(t, 3, len(paths))
+	assert.Equal(t, 1<<20, search.Stats().PathsFound)
+}
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// Walk visits every Node within maxDepth hops of any of the roots, breadth first, lazily loading Nodes as it reaches them.
+// visit is called once for every Node after it has been loaded, along with its distance from the nearest root.
+// Nodes that fail to load are visited without data. Returns the context's error if it is done before the walk completes.
+func Walk(ctx context.Context, roots []*Node, maxDepth int, visit func(node *Node, depth int)) error {
+	if len(roots) == 0 {
+		return nil
+	}
+	loader := roots[0].group.loader
+
+	visited := make(map[string]bool)
+	frontier := []*Node{}
+	for _, root := range roots {
+		if !visited[root.ID] {
+			visited[root.ID] = true
+			frontier = append(frontier, root)
+		}
+	}
+	for depth := 0; depth <= maxDepth && len(frontier) > 0; depth++ {
+		if debug {
+			fmt.Printf("Walk depth %v: %v node(s) in frontier\n", depth, len(frontier))
+		}
+		loader.LoadAll(ctx, frontier)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		next := []*Node{}
+		for _, node := range frontier {
+			visit(node, depth)
+			for _, neighbour := range node.Neighbours() {
+				if !visited[neighbour.ID] {
+					visited[neighbour.ID] = true
+					next = append(next, neighbour)
+				}
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWalkVisitsNodesBreadthFirstUpToMaxDepth(t *testing.T) {
+	/*
+	   A--B--C--D
+	   |
+	   E     F--G
+	*/
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+	e := NewNode("E", nil, group)
+	f := NewNode("F", nil, group)
+	g := NewNode("G", nil, group)
+	a.Connect(b)
+	b.Connect(c)
+	c.Connect(d)
+	a.Connect(e)
+	f.Connect(g)
+
+	depths := map[string]int{}
+	err := Walk(context.Background(), []*Node{a}, 2, func(node *Node, depth int) {
+		assert.True(t, node.HasData())
+		depths[node.ID] = depth
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"A": 0, "B": 1, "E": 1, "C": 2}, depths)
+
+	depths = map[string]int{}
+	Walk(context.Background(), []*Node{d, g}, 1, func(node *Node, depth int) { depths[node.ID] = depth })
+	assert.Equal(t, map[string]int{"D": 0, "G": 0, "C": 1, "F": 1}, depths)
+}
+
+func TestWalkStopsWhenContextIsDone(t *testing.T) {
+	var blockingLoader ContextNodeFetcher = func(ctx context.Context, n *Node) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	a := NewNode("A", blockingLoader, NewNodeGroup())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	visits := 0
+	err := Walk(ctx, []*Node{a}, 3, func(node *Node, depth int) { visits++ })
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 0, visits)
+}
@@ -0,0 +1,51 @@
+package graph
+
+import "context"
+
+// PathViaTo computes a path from the current node to the target node that visits each of the waypoints, in order.
+// It returns nil when no such path is available within the NodeGroup's maximum recursion depth.
+func (n *Node) PathViaTo(target *Node, waypoints ...*Node) Path {
+	path, _ := n.PathViaToContext(context.Background(), target, waypoints...)
+	return path
+}
+
+// PathViaToContext is PathViaTo, but stops loading Nodes and returns as soon as the context is done.
+// It returns nil along with the context's error when the search could not be completed.
+func (n *Node) PathViaToContext(ctx context.Context, target *Node, waypoints ...*Node) (Path, error) {
+	search := NewSearch(ctx, n.group)
+	defer search.Cancel()
+	return search.PathVia(n, target, waypoints...)
+}
+
+// PathVia computes a path from the source node to the target node that visits each of the waypoints, in order.
+// The path is stitched together from the shortest path between each consecutive pair of stops. Every segment avoids the
+// nodes of the segments before it, so that the path never visits a node twice, which means a path may be missed when
+// the only way to reach a waypoint goes through the shortest route to an earlier one.
+// Returns nil if there is no such path within the Search's maximum depth, along with the Search's error if it was stopped.
+func (s *Search) PathVia(source, target *Node, waypoints ...*Node) (Path, error) {
+	stops := append(append([]*Node{source}, waypoints...), target)
+	path := Path{source}
+	blocked := &blocklist{nodes: make(map[string]bool), edges: make(map[[2]string]bool)}
+	for i := 0; i+1 < len(stops); i++ {
+		for _, node := range path[:len(path)-1] {
+			blocked.nodes[node.ID] = true
+		}
+		// Later stops must be left for later segments to reach
+		for _, stop := range stops[i+2:] {
+			if !stop.Equal(stops[i+1]) {
+				blocked.nodes[stop.ID] = true
+			}
+		}
+
+		segment, err := s.shortestPath(stops[i], stops[i+1], s.maxDepth-(len(path)-1), blocked)
+		if segment == nil {
+			return nil, err
+		}
+		path = append(path, segment[1:]...)
+		for _, stop := range stops[i+2:] {
+			delete(blocked.nodes, stop.ID)
+		}
+	}
+	s.foundPaths(1)
+	return path, nil
+}
@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSearchesNeverTraverseAvoidedNodes(t *testing.T) {
+	group, a, d := roads()
+	b, _ := group.Get("B")
+
+	searches := map[string]func(s *Search) []Path{
+		"PathsTo": func(s *Search) []Path {
+			paths, _ := s.PathsTo(a, d)
+			return paths
+		},
+		"ShortestPath": func(s *Search) []Path {
+			path, _ := s.ShortestPath(a, d)
+			return []Path{path}
+		},
+		"BidirectionalPath": func(s *Search) []Path {
+			path, _ := s.BidirectionalPath(a, d)
+			return []Path{path}
+		},
+		"AllShortestPaths": func(s *Search) []Path {
+			paths, _ := s.AllShortestPaths(a, d)
+			return paths
+		},
+		"KShortestPaths": func(s *Search) []Path {
+			paths, _ := s.KShortestPaths(a, d, 5)
+			return paths
+		},
+		"WeightedPath": func(s *Search) []Path {
+			path, _, _ := s.WeightedPath(a, d)
+			return []Path{path}
+		},
+		"PathVia": func(s *Search) []Path {
+			path, _ := s.PathVia(a, d)
+			return []Path{path}
+		},
+	}
+	for name, search := range searches {
+		s := NewSearch(context.Background(), group)
+		s.Avoid(b)
+		if paths := fmt.Sprint(search(s)); paths != "[A -> C -> D]" {
+			t.Errorf("Expected %v to only find [A -> C -> D], got %v", name, paths)
+		}
+	}
+}
+
+func TestAvoidingTheTargetFindsNoPath(t *testing.T) {
+	group, a, d := roads()
+
+	s := NewSearch(context.Background(), group)
+	s.Avoid(d)
+	path, err := s.BidirectionalPath(a, d)
+	assert.Nil(t, path)
+	assert.Nil(t, err)
+	assert.False(t, s.Found())
+}
+
+func TestPathViaVisitsWaypointsInOrder(t *testing.T) {
+	group, a, d := roads()
+	b, _ := group.Get("B")
+	c, _ := group.Get("C")
+
+	assert.Equal(t, "A -> C -> D", a.PathViaTo(d, c).String())
+	assert.Equal(t, "A -> B -> D", a.PathViaTo(d, b).String())
+	assert.Equal(t, "D -> C -> A -> B", d.PathViaTo(b, c).String())
+	assert.Equal(t, "A -> B -> D", a.PathViaTo(d).String())
+}
+
+func TestPathViaNeverRevisitsANode(t *testing.T) {
+	group, a, d := roads()
+	b, _ := group.Get("B")
+	c, _ := group.Get("C")
+
+	// Reaching C after B would have to go back through A or D
+	assert.Nil(t, a.PathViaTo(d, b, c))
+	assert.Equal(t, "A -> B -> D -> C", a.PathViaTo(c, b, d).String())
+}
+
+func TestPathViaKeepsLaterWaypointsForLaterSegments(t *testing.T) {
+	/*
+	   A--B--C
+	   |     |
+	   D-----E
+	*/
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+	e := NewNode("E", nil, group)
+	a.Connect(b)
+	b.Connect(c)
+	a.Connect(d)
+	d.Connect(e)
+	e.Connect(c)
+
+	// The shortest path from A to C goes through B, which must be left for the second segment
+	assert.Equal(t, "A -> D -> E -> C -> B", a.PathViaTo(b, c).String())
+}
+
+func TestPathViaStaysWithinTheMaximumDepth(t *testing.T) {
+	group, a, d := roads()
+	c, _ := group.Get("C")
+
+	s := NewSearch(context.Background(), group, 2)
+	path, err := s.PathVia(a, d, c)
+	assert.Nil(t, err)
+	assert.Equal(t, "A -> C -> D", path.String())
+
+	s = NewSearch(context.Background(), group, 1)
+	path, err = s.PathVia(a, d, c)
+	assert.Nil(t, err)
+	assert.Nil(t, path)
+}
+
+func TestPathViaStopsWhenCancelled(t *testing.T) {
+	group, a, d := roads()
+	c, _ := group.Get("C")
+
+	s := NewSearch(context.Background(), group)
+	s.Cancel()
+	path, err := s.PathVia(a, d, c)
+	assert.Nil(t, path)
+	assert.Equal(t, context.Canceled, err)
+}
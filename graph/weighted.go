@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+)
+
+// Heuristic estimates the total weight of the lightest path from a node to the target node, to guide an A* search.
+// It must never overestimate the weight for the search to find the lightest path.
+type Heuristic func(node, target *Node) float64
+
+// WeightedPathTo computes the lightest path from the current node to the target node, along with its total weight.
+// It returns nil when no path is available within the NodeGroup's maximum recursion depth.
+// Parameter 1: heuristic - Heuristic to search with A* instead of Dijkstra's algorithm. Defaults to none.
+func (n *Node) WeightedPathTo(target *Node, args ...interface{}) (Path, float64) {
+	path, weight, _ := n.WeightedPathToContext(context.Background(), target, args...)
+	return path, weight
+}
+
+// WeightedPathToContext is WeightedPathTo, but stops loading Nodes and returns as soon as the context is done.
+// It returns nil along with the context's error when the search could not be completed.
+func (n *Node) WeightedPathToContext(ctx context.Context, target *Node, args ...interface{}) (Path, float64, error) {
+	search := NewSearch(ctx, n.group)
+	defer search.Cancel()
+	return search.WeightedPath(n, target, args...)
+}
+
+// WeightedPath computes the path from the source node to the target node with the lowest total Edge weight, using
+// Dijkstra's algorithm, or A* when given a Heuristic. Nodes are lazily loaded one at a time, as the search settles them.
+// The search keeps the lightest path to each node for every number of hops, so that a heavier path with fewer hops is
+// still followed when the lighter one would exceed the Search's maximum depth. The lightest path within the maximum
+// depth is returned, or nil if the target can not be reached, along with the Search's error if it was stopped.
+// Parameter 1: heuristic - Heuristic to search with A* instead of Dijkstra's algorithm. Defaults to none.
+func (s *Search) WeightedPath(source, target *Node, args ...interface{}) (Path, float64, error) {
+	var heuristic Heuristic
+	if len(args) > 0 && args[0] != nil {
+		heuristic = args[0].(Heuristic)
+	}
+	estimate := func(node *Node) float64 {
+		if heuristic == nil {
+			return 0
+		}
+		return heuristic(node, target)
+	}
+
+	s.visit(source)
+	weights := map[weightedLabel]float64{weightedLabel{source.ID, 0}: 0}
+	queue := &weightedQueue{}
+	heap.Push(queue, &weightedItem{node: source, priority: estimate(source)})
+	for queue.Len() > 0 {
+		if err := s.Err(); err != nil {
+			return nil, 0, err
+		}
+		item := heap.Pop(queue).(*weightedItem)
+		node := item.node
+		if item.weight > weights[weightedLabel{node.ID, item.hops}] || dominated(weights, node.ID, item.hops-1, item.weight) {
+			// A lighter path to this node, with no more hops, has been found since this item was queued
+			continue
+		}
+		if node.Equal(target) {
+			s.foundPaths(1)
+			return item.path(), item.weight, nil
+		}
+		if item.hops >= s.maxDepth {
+			continue
+		}
+
+		if debug {
+			fmt.Printf("Weighted search: expanding %v at weight %v after %v hops\n", node, item.weight, item.hops)
+		}
+		if err := s.group.loader.Load(s.ctx, node); err != nil {
+			continue
+		}
+		s.expanded(1)
+
+		for _, edge := range node.Edges() {
+			if !s.allows(edge) {
+				continue
+			}
+			neighbour := edge.To
+			weight := item.weight + edge.Weight
+			if dominated(weights, neighbour.ID, item.hops+1, weight) {
+				continue
+			}
+			weights[weightedLabel{neighbour.ID, item.hops + 1}] = weight
+			s.visit(neighbour)
+			heap.Push(queue, &weightedItem{node: neighbour, hops: item.hops + 1, weight: weight,
+				priority: weight + estimate(neighbour), parent: item})
+		}
+	}
+	return nil, 0, s.Err()
+}
+
+// weightedLabel identifies the paths a weighted search has found to a node with a given number of hops.
+type weightedLabel struct {
+	id   string
+	hops int
+}
+
+// dominated returns true if a path at most as heavy as the given weight, with at most the given number of hops, is
+// already known to the node with the given ID.
+func dominated(weights map[weightedLabel]float64, id string, hops int, weight float64) bool {
+	for h := 0; h <= hops; h++ {
+		if known, reached := weights[weightedLabel{id, h}]; reached && known <= weight {
+			return true
+		}
+	}
+	return false
+}
+
+// weightedItem is a node queued for expansion by a weighted search, along with the path found to it and its weight.
+type weightedItem struct {
+	node     *Node
+	hops     int
+	weight   float64
+	priority float64
+	parent   *weightedItem
+}
+
+// path returns the path the weighted search followed to reach the item's node.
+func (item *weightedItem) path() Path {
+	path := Path{}
+	for ; item != nil; item = item.parent {
+		path = append(Path{item.node}, path...)
+	}
+	return path
+}
+
+// weightedQueue is a priority queue of weightedItems, lowest priority first, and ordered by node ID for equal priorities.
+type weightedQueue []*weightedItem
+
+func (q weightedQueue) Len() int { return len(q) }
+func (q weightedQueue) Less(i, j int) bool {
+	if q[i].priority == q[j].priority {
+		return q[i].node.ID < q[j].node.ID
+	}
+	return q[i].priority < q[j].priority
+}
+func (q weightedQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *weightedQueue) Push(x interface{}) { *q = append(*q, x.(*weightedItem)) }
+func (q *weightedQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
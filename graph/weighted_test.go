@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeightedPathComputation(t *testing.T) {
+	/*
+	     B--1--C
+	    1|     |1
+	   A-+--5--D--1--E
+	*/
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+	e := NewNode("E", nil, group)
+	a.Connect(b, nil, 1.0)
+	b.Connect(c, nil, 1.0)
+	c.Connect(d, nil, 1.0)
+	a.Connect(d, nil, 5.0)
+	d.Connect(e, nil, 1.0)
+
+	path, weight := a.WeightedPathTo(e)
+	assert.Equal(t, "A -> B -> C -> D -> E", path.String())
+	assert.Equal(t, 4.0, weight)
+	assert.Equal(t, 4.0, path.Weight())
+
+	path = a.PathsTo(e, true)[0]
+	assert.Equal(t, "A -> D -> E", path.String())
+	assert.Equal(t, 6.0, path.Weight())
+
+	unconnected := NewNode("unconnected", nil, group)
+	path, _ = a.WeightedPathTo(unconnected)
+	assert.Nil(t, path)
+}
+
+func TestWeightedPathWithAHeuristic(t *testing.T) {
+	group := NewNodeGroup()
+	nodes := make(map[string]*Node)
+	for _, id := range []string{"A", "B", "C", "D"} {
+		nodes[id] = NewNode(id, nil, group)
+	}
+	nodes["A"].Connect(nodes["B"], nil, 2.0)
+	nodes["B"].Connect(nodes["D"], nil, 2.0)
+	nodes["A"].Connect(nodes["C"], nil, 1.0)
+	nodes["C"].Connect(nodes["D"], nil, 4.0)
+
+	var heuristic Heuristic = func(node, target *Node) float64 {
+		if node.Equal(target) {
+			return 0
+		}
+		return 1
+	}
+	search := NewSearch(context.Background(), group)
+	path, weight, err := search.WeightedPath(nodes["A"], nodes["D"], heuristic)
+	assert.Nil(t, err)
+	assert.Equal(t, "A -> B -> D", path.String())
+	assert.Equal(t, 4.0, weight)
+	assert.True(t, search.Found())
+}
+
+func TestWeightedPathRespectsMaxDepth(t *testing.T) {
+	group := NewNodeGroup(2)
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	d := NewNode("D", nil, group)
+	a.Connect(b, nil, 1.0)
+	b.Connect(c, nil, 1.0)
+	c.Connect(d, nil, 1.0)
+	a.Connect(d, nil, 10.0)
+
+	path, weight := a.WeightedPathTo(d)
+	assert.Equal(t, "A -> D", path.String())
+	assert.Equal(t, 10.0, weight)
+
+	search := NewSearch(context.Background(), group)
+	search.Cancel()
+	path, _, err := search.WeightedPath(a, d)
+	assert.Nil(t, path)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestWeightedPathFollowsHeavierPathsWithFewerHops(t *testing.T) {
+	/*
+	   S--0.1--X1--0.1--X2--1--T
+	   |                |
+	   +-------5--------+
+	*/
+	group := NewNodeGroup(2)
+	s := NewNode("S", nil, group)
+	x1 := NewNode("X1", nil, group)
+	x2 := NewNode("X2", nil, group)
+	target := NewNode("T", nil, group)
+	s.Connect(x1, nil, 0.1)
+	x1.Connect(x2, nil, 0.1)
+	s.Connect(x2, nil, 5.0)
+	x2.Connect(target, nil, 1.0)
+
+	assert.Equal(t, "S -> X2 -> T", s.PathsTo(target, true)[0].String())
+	path, weight := s.WeightedPathTo(target)
+	assert.Equal(t, "S -> X2 -> T", path.String())
+	assert.Equal(t, 6.0, weight)
+
+	path, weight = s.WeightedPathTo(x2)
+	assert.Equal(t, "S -> X1 -> X2", path.String())
+	assert.InDelta(t, 0.2, weight, 1e-9)
+}
+
+func TestConnectWeighsEdges(t *testing.T) {
+	group := NewNodeGroup()
+	a := NewNode("A", nil, group)
+	b := NewNode("B", nil, group)
+	c := NewNode("C", nil, group)
+	a.Connect(b, "data", 2.5)
+	a.Connect(b, nil, 7.0)
+	a.Connect(c)
+
+	edge, _ := b.EdgeTo(a)
+	assert.Equal(t, 2.5, edge.Weight)
+	assert.Equal(t, []Edge{Edge{From: a, To: b, Data: "data", Weight: 2.5}, Edge{From: a, To: c, Weight: 1}}, a.Edges())
+}
@@ -0,0 +1,127 @@
+package moviebuff
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DiskCache stores fetched Moviebuff entities on disk, one JSON file per URL slug.
+// Cached entities expire once they are older than the cache's TTL.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// cacheEntry is a cached entity, along with when it was fetched, and the validators needed to revalidate it.
+type cacheEntry struct {
+	Entity       *mbEntity `json:"entity"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+}
+
+// CacheStats describes the contents of a DiskCache.
+type CacheStats struct {
+	Entities int
+	People   int
+	Movies   int
+	Stale    int
+	Bytes    int64
+}
+
+// NewDiskCache creates a DiskCache storing entities under dir, and expiring them after ttl.
+// A ttl of 0 or less never expires entities.
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{dir: dir, ttl: ttl}
+}
+
+// Stats scans the cache directory and describes the entities cached in it.
+// An empty CacheStats is returned if nothing has been cached yet.
+func (c *DiskCache) Stats() (CacheStats, error) {
+	stats := CacheStats{}
+	files, err := ioutil.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		id, err := url.PathUnescape(strings.TrimSuffix(file.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		entry, cached := c.get(id)
+		if !cached {
+			continue
+		}
+		stats.Entities++
+		stats.Bytes += file.Size()
+		switch entry.Entity.Type {
+		case "Person":
+			stats.People++
+		case "Movie":
+			stats.Movies++
+		}
+		if !c.fresh(entry) {
+			stats.Stale++
+		}
+	}
+	return stats, nil
+}
+
+// get returns the cached entry for an ID, whether or not it has expired.
+func (c *DiskCache) get(id string) (*cacheEntry, bool) {
+	content, err := ioutil.ReadFile(c.path(id))
+	if err != nil {
+		return nil, false
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(content, entry); err != nil || entry.Entity == nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// put caches an entry for an ID.
+// The entry is written to a temporary file first, so that concurrent readers never see a partially written entry.
+func (c *DiskCache) put(id string, entry *cacheEntry) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, errWrite := file.Write(content)
+	errClose := file.Close()
+	if errWrite == nil {
+		errWrite = errClose
+	}
+	if errWrite != nil {
+		os.Remove(file.Name())
+		return errWrite
+	}
+	return os.Rename(file.Name(), c.path(id))
+}
+
+// fresh returns true if the entry has not expired yet.
+func (c *DiskCache) fresh(entry *cacheEntry) bool {
+	return c.ttl <= 0 || time.Since(entry.FetchedAt) < c.ttl
+}
+
+func (c *DiskCache) path(id string) string {
+	return filepath.Join(c.dir, url.PathEscape(id)+".json")
+}
@@ -0,0 +1,65 @@
+package moviebuff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCacheStoresAndRetrievesEntities(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "moviebuff-cache")
+	defer os.RemoveAll(dir)
+	cache := NewDiskCache(dir, time.Hour)
+
+	_, cached := cache.get("an-actor")
+	assert.False(t, cached)
+
+	entity := &mbEntity{URL: "an-actor", Name: "An Actor", Type: "Person",
+		Movies: []mbConnection{mbConnection{URL: "a-movie", Name: "A Movie", Role: "Actor"}}}
+	assert.Nil(t, cache.put("an-actor", &cacheEntry{Entity: entity, FetchedAt: time.Now()}))
+
+	entry, cached := cache.get("an-actor")
+	assert.True(t, cached)
+	assert.Equal(t, entity, entry.Entity)
+	assert.True(t, cache.fresh(entry))
+}
+
+func TestDiskCacheExpiresEntities(t *testing.T) {
+	cache := NewDiskCache("", time.Hour)
+	assert.True(t, cache.fresh(&cacheEntry{FetchedAt: time.Now().Add(-59 * time.Minute)}))
+	assert.False(t, cache.fresh(&cacheEntry{FetchedAt: time.Now().Add(-61 * time.Minute)}))
+
+	cache = NewDiskCache("", 0)
+	assert.True(t, cache.fresh(&cacheEntry{FetchedAt: time.Now().Add(-1000 * time.Hour)}))
+}
+
+func TestDiskCacheKeepsSlugsInsideItsDirectory(t *testing.T) {
+	cache := NewDiskCache("/cache", time.Hour)
+	assert.Equal(t, "/cache/..%2Fetc%2Fpasswd.json", cache.path("../etc/passwd"))
+}
+
+func TestDiskCacheStats(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "moviebuff-cache")
+	defer os.RemoveAll(dir)
+	cache := NewDiskCache(dir, time.Hour)
+
+	stats, err := NewDiskCache(filepath.Join(dir, "missing"), time.Hour).Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, CacheStats{}, stats)
+
+	cache.put("an-actor", &cacheEntry{Entity: &mbEntity{URL: "an-actor", Type: "Person"}, FetchedAt: time.Now()})
+	cache.put("a-director", &cacheEntry{Entity: &mbEntity{URL: "a-director", Type: "Person"}, FetchedAt: time.Now().Add(-2 * time.Hour)})
+	cache.put("a-movie", &cacheEntry{Entity: &mbEntity{URL: "a-movie", Type: "Movie"}, FetchedAt: time.Now()})
+	ioutil.WriteFile(filepath.Join(dir, "not-an-entity.txt"), []byte("junk"), 0644)
+
+	stats, err = cache.Stats()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, stats.Entities)
+	assert.Equal(t, 2, stats.People)
+	assert.Equal(t, 1, stats.Movies)
+	assert.Equal(t, 1, stats.Stale)
+	assert.True(t, stats.Bytes > 0)
+}
@@ -0,0 +1,65 @@
+package moviebuff
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DirSource is a Source that reads Moviebuff entities from a local directory, such as a mirror of the Moviebuff S3 bucket.
+// Each entity is stored in a JSON file named after its URL slug, either as <dir>/<slug>, or gzipped as <dir>/<slug>.gz.
+type DirSource struct {
+	dir string
+}
+
+// NewDirSource creates a DirSource reading entities from dir.
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// Entity reads the Moviebuff entity with the given ID/URL slug from the directory.
+func (s *DirSource) Entity(ctx context.Context, id string) (*Entity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if id == "" || id != filepath.Base(id) || id == ".." {
+		return nil, graph.Permanent(fmt.Errorf("invalid entity ID: %q", id))
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, id))
+	if os.IsNotExist(err) {
+		file, err = os.Open(filepath.Join(s.dir, id+".gz"))
+	}
+	if os.IsNotExist(err) {
+		return nil, graph.Permanent(fmt.Errorf("entity not found: %v", id))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := decompress(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading %v: %v", id, err)
+	}
+	entity, err := decodeEntity(reader)
+	if err != nil {
+		return nil, graph.Permanent(fmt.Errorf("decoding %v: %v", id, err))
+	}
+	return entity.entity(), nil
+}
+
+// decompress transparently gunzips the file if it starts with the gzip magic number, whatever it is named.
+func decompress(file io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(file)
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
@@ -0,0 +1,49 @@
+package moviebuff
+
+import (
+	"compress/gzip"
+	"context"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirSourceReadsPlainAndGzippedEntities(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "moviebuff-dump")
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "an-actor"), []byte(`{"url":"an-actor","type":"Person","name":"An Actor",
+	"movies":[{"name":"A Movie","url":"a-movie","role":"Actor"}]}`), 0644)
+	file, _ := os.Create(filepath.Join(dir, "a-movie.gz"))
+	zipper := gzip.NewWriter(file)
+	zipper.Write([]byte(`{"url":"a-movie","type":"Movie","name":"A Movie",
+	"cast":[{"url":"an-actor","name":"An Actor","role":"Actor"}]}`))
+	zipper.Close()
+	file.Close()
+
+	source := NewDirSource(dir)
+	actor, err := source.Entity(context.Background(), "an-actor")
+	assert.Nil(t, err)
+	assert.Equal(t, &Entity{ID: "an-actor", Name: "An Actor", Type: "Person",
+		Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Actor"}}}, actor)
+
+	movie, err := source.Entity(context.Background(), "a-movie")
+	assert.Nil(t, err)
+	assert.Equal(t, "A Movie", movie.Name)
+	assert.Equal(t, []Neighbour{Neighbour{ID: "an-actor", Name: "An Actor", Role: "Actor"}}, movie.Neighbours)
+}
+
+func TestDirSourceReportsMissingEntitiesAsPermanentErrors(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "moviebuff-dump")
+	defer os.RemoveAll(dir)
+	source := NewDirSource(dir)
+
+	entity, err := source.Entity(context.Background(), "a-missing-person")
+	assert.Nil(t, entity)
+	assert.True(t, graph.IsPermanent(err))
+
+	_, err = source.Entity(context.Background(), "../etc/passwd")
+	assert.True(t, graph.IsPermanent(err))
+}
@@ -0,0 +1,37 @@
+package moviebuff
+
+import (
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"strings"
+)
+
+// NewRoleFilter returns a graph.Filter that only traverses credits for the allowed roles, and never those for denied roles.
+// Roles are matched ignoring case. Every role is allowed if allow is empty, and denied roles take precedence over
+// allowed ones, so a credit for several roles is traversed if any of them is allowed and none of them is denied.
+// Edges without a Credit are always traversed.
+func NewRoleFilter(allow, deny []string) graph.Filter {
+	allowed, denied := roleSet(allow), roleSet(deny)
+	return func(edge graph.Edge) bool {
+		credit, isCredit := edge.Data.(Credit)
+		if !isCredit {
+			return true
+		}
+		anyAllowed := len(allowed) == 0
+		for _, role := range credit.Roles {
+			role = strings.ToLower(role)
+			if denied[role] {
+				return false
+			}
+			anyAllowed = anyAllowed || allowed[role]
+		}
+		return anyAllowed
+	}
+}
+
+func roleSet(roles []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, role := range roles {
+		set[strings.ToLower(strings.TrimSpace(role))] = true
+	}
+	return set
+}
@@ -0,0 +1,60 @@
+package moviebuff
+
+import (
+	"context"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRoleFilterAllowsAndDeniesRoles(t *testing.T) {
+	credited := func(role string) graph.Edge { return graph.Edge{Data: Credit{Roles: []string{role}}} }
+
+	onlyActors := NewRoleFilter([]string{"Actor", "actress"}, nil)
+	assert.True(t, onlyActors(credited("actor")))
+	assert.True(t, onlyActors(credited("Actress")))
+	assert.False(t, onlyActors(credited("Director")))
+	assert.True(t, onlyActors(graph.Edge{}))
+
+	noCrew := NewRoleFilter(nil, []string{"Director", "Producer"})
+	assert.True(t, noCrew(credited("Actor")))
+	assert.False(t, noCrew(credited("producer")))
+
+	assert.False(t, NewRoleFilter([]string{"Actor"}, []string{"Actor"})(credited("Actor")))
+}
+
+func TestRoleFilterChecksEveryRoleOfACredit(t *testing.T) {
+	actorAndDirector := graph.Edge{Data: Credit{Roles: []string{"Actor", "Director"}}}
+	assert.True(t, NewRoleFilter([]string{"Actor"}, nil)(actorAndDirector))
+	assert.True(t, NewRoleFilter([]string{"Director"}, nil)(actorAndDirector))
+	assert.False(t, NewRoleFilter([]string{"Producer"}, nil)(actorAndDirector))
+	assert.False(t, NewRoleFilter(nil, []string{"Director"})(actorAndDirector))
+	assert.False(t, NewRoleFilter([]string{"Actor"}, []string{"director"})(actorAndDirector))
+}
+
+func TestRoleFilterRestrictsSearches(t *testing.T) {
+	source := mockSource{
+		"an-actor": &Entity{ID: "an-actor", Name: "An Actor", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Actor"}}},
+		"a-movie": &Entity{ID: "a-movie", Name: "A Movie", Type: "Movie",
+			Neighbours: []Neighbour{Neighbour{ID: "an-actor", Name: "An Actor", Role: "Actor"},
+				Neighbour{ID: "a-director", Name: "A Director", Role: "Director"}}},
+		"a-director": &Entity{ID: "a-director", Name: "A Director", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Director"}}},
+	}
+	group := graph.NewNodeGroup()
+	fetch := NewFetcher(source)
+	actor := graph.NewNode("an-actor", fetch, group)
+	director := graph.NewNode("a-director", fetch, group)
+
+	search := graph.NewSearch(context.Background(), group)
+	search.SetFilter(NewRoleFilter([]string{"Actor"}, nil))
+	path, err := search.BidirectionalPath(actor, director)
+	assert.Nil(t, err)
+	assert.Nil(t, path)
+
+	search = graph.NewSearch(context.Background(), group)
+	search.SetFilter(NewRoleFilter(nil, []string{"Producer"}))
+	path, _ = search.BidirectionalPath(actor, director)
+	assert.Equal(t, "an-actor -> a-movie -> a-director", path.String())
+}
@@ -1,19 +1,96 @@
 package moviebuff
 
 import (
-	"../graph"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBaseURL           = "http://data.moviebuff.com"
+	defaultRequestsPerSecond = 20
+	defaultBurst             = 10
 )
 
 var (
-	baseURL    = "http://data.moviebuff.com"
-	httpClient = &http.Client{}
+	defaultClient  = NewClient(Config{})
+	defaultFetcher = NewFetcher(defaultClient)
 )
 
+// Config configures a Client. The zero value is a valid Config that talks to data.moviebuff.com with default limits.
+type Config struct {
+	// BaseURL is the URL entity slugs are relative to. Defaults to http://data.moviebuff.com.
+	BaseURL string
+	// HTTPClient makes the requests. Defaults to a new http.Client.
+	HTTPClient *http.Client
+	// RequestsPerSecond is the average number of requests allowed per second. Defaults to 20. Negative values disable rate limiting.
+	RequestsPerSecond float64
+	// Burst is the number of requests allowed in a burst. Defaults to 10.
+	Burst int
+	// Cache stores fetched entities on disk. Entities are not cached if nil.
+	Cache *DiskCache
+}
+
+// Client is a Source that fetches Moviebuff entities over HTTP, without exceeding its rate limit.
+// Entities are served from its DiskCache, if it has one, until they expire.
+type Client struct {
+	requests   int64
+	baseURL    string
+	httpClient *http.Client
+	limiter    *RateLimiter
+	cache      *DiskCache
+}
+
+// NewClient creates a new rate limited Client from the given Config.
+func NewClient(config Config) *Client {
+	if config.BaseURL == "" {
+		config.BaseURL = defaultBaseURL
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{}
+	}
+	if config.RequestsPerSecond == 0 {
+		config.RequestsPerSecond = defaultRequestsPerSecond
+	}
+	if config.Burst == 0 {
+		config.Burst = defaultBurst
+	}
+	return &Client{baseURL: config.BaseURL,
+		httpClient: config.HTTPClient,
+		limiter:    NewRateLimiter(config.RequestsPerSecond, config.Burst),
+		cache:      config.Cache}
+}
+
+// Requests returns the number of HTTP requests the Client has made.
+func (c *Client) Requests() int {
+	return int(atomic.LoadInt64(&c.requests))
+}
+
+// requestCounterKey is the context key of the counter set by WithRequestCounter.
+type requestCounterKey struct{}
+
+// WithRequestCounter returns a context that makes Clients add every HTTP request they make with it to the counter,
+// so that the requests made for one query can be counted while other queries share the same Client.
+func WithRequestCounter(ctx context.Context, counter *int64) context.Context {
+	return context.WithValue(ctx, requestCounterKey{}, counter)
+}
+
+// Entity fetches the Moviebuff entity with the given ID/URL slug.
+func (c *Client) Entity(ctx context.Context, id string) (*Entity, error) {
+	entity, err := c.fetchEntity(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return entity.entity(), nil
+}
+
 type mbEntity struct {
 	URL    string         `json:"url"`
 	Name   string         `json:"name"`
@@ -28,10 +105,52 @@ type mbConnection struct {
 	Role string `json:"role"`
 }
 
-func fetchEntity(id string) (*mbEntity, error) {
-	entityURL := baseURL + "/" + id
+func (c *Client) fetchEntity(ctx context.Context, id string) (*mbEntity, error) {
+	var stale *cacheEntry
+	if c.cache != nil {
+		if entry, cached := c.cache.get(id); cached {
+			if c.cache.fresh(entry) {
+				return entry.Entity, nil
+			}
+			stale = entry
+		}
+	}
+
+	entry, err := c.download(ctx, id, stale)
+	if err != nil {
+		return nil, err
+	}
+	if c.cache != nil {
+		c.cache.put(id, entry)
+	}
+	return entry.Entity, nil
+}
 
-	response, errHTTP := httpClient.Get(entityURL)
+// download fetches an entity from Moviebuff over HTTP.
+// If a stale cache entry is given, the request is made conditional on the entity having changed since,
+// and the stale entity is reused if the server responds with 304 Not Modified.
+func (c *Client) download(ctx context.Context, id string, stale *cacheEntry) (*cacheEntry, error) {
+	entityURL := c.baseURL + "/" + id
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	request, errRequest := http.NewRequestWithContext(ctx, http.MethodGet, entityURL, nil)
+	if errRequest != nil {
+		return nil, errRequest
+	}
+	if stale != nil && stale.ETag != "" {
+		request.Header.Set("If-None-Match", stale.ETag)
+	}
+	if stale != nil && stale.LastModified != "" {
+		request.Header.Set("If-Modified-Since", stale.LastModified)
+	}
+	atomic.AddInt64(&c.requests, 1)
+	if counter, counting := ctx.Value(requestCounterKey{}).(*int64); counting {
+		atomic.AddInt64(counter, 1)
+	}
+	response, errHTTP := c.httpClient.Do(request)
 	if errHTTP != nil {
 		return nil, errHTTP
 	}
@@ -40,16 +159,45 @@ func fetchEntity(id string) (*mbEntity, error) {
 		defer response.Body.Close()
 	}
 
+	if isThrottled(response) {
+		c.limiter.Throttle(retryAfter(response))
+	} else {
+		c.limiter.Recover()
+	}
+
+	if stale != nil && response.StatusCode == http.StatusNotModified {
+		return &cacheEntry{Entity: stale.Entity,
+			ETag:         headerOr(response, "ETag", stale.ETag),
+			LastModified: headerOr(response, "Last-Modified", stale.LastModified),
+			FetchedAt:    time.Now()}, nil
+	}
+
 	if response.StatusCode != 200 {
 		responseBytes, err := ioutil.ReadAll(response.Body)
 		if err != nil {
 			return nil, errors.New("unknown error")
 		}
-		return nil, fmt.Errorf("server error: %v: %v", response.StatusCode, string(responseBytes))
+		errServer := fmt.Errorf("server error: %v: %v", response.StatusCode, string(responseBytes))
+		if isPermanentStatus(response.StatusCode) {
+			return nil, graph.Permanent(errServer)
+		}
+		return nil, errServer
 	}
 
+	entity, errDecode := decodeEntity(response.Body)
+	if errDecode != nil {
+		return nil, errDecode
+	}
+	return &cacheEntry{Entity: entity,
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now()}, nil
+}
+
+// decodeEntity decodes a Moviebuff JSON entity.
+func decodeEntity(r io.Reader) (*mbEntity, error) {
 	entity := &mbEntity{}
-	dec := json.NewDecoder(response.Body)
+	dec := json.NewDecoder(r)
 	errDecode := dec.Decode(&entity)
 	if errDecode != nil {
 		return nil, errDecode
@@ -57,23 +205,29 @@ func fetchEntity(id string) (*mbEntity, error) {
 	return entity, nil
 }
 
-// Fetch fetches moviebuff content given an ID/URL, and populates Neighbours of the Node.
-func Fetch(n *graph.Node) error {
-	entity, err := fetchEntity(n.ID)
-	if err != nil {
-		return err
+// headerOr returns the value of a response header, or the fallback value if the header is missing.
+func headerOr(response *http.Response, header string, fallback string) string {
+	if value := response.Header.Get(header); value != "" {
+		return value
 	}
-	var connections []mbConnection
-	if entity.Type == "Person" {
-		connections = entity.Movies
-	} else {
-		connections = entity.Cast
+	return fallback
+}
+
+// isPermanentStatus returns true for HTTP status codes that retrying the request won't change, like 404 Not Found.
+func isPermanentStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
 	}
+	return statusCode >= 400 && statusCode < 500
+}
 
-	n.SetData(entity)
+// Fetch fetches moviebuff content given an ID/URL from data.moviebuff.com with default limits, and populates Neighbours of the Node.
+func Fetch(n *graph.Node) error {
+	return FetchContext(context.Background(), n)
+}
 
-	for _, connection := range connections {
-		n.Connect(graph.NewNode(connection.URL, graph.NodeFetcher(Fetch)))
-	}
-	return nil
+// FetchContext is Fetch, but abandons the request when the context is done.
+func FetchContext(ctx context.Context, n *graph.Node) error {
+	return defaultFetcher(ctx, n)
 }
@@ -1,13 +1,17 @@
 package moviebuff
 
 import (
-	"../graph"
+	"context"
 	"errors"
 	"fmt"
+	"github.com/CodeMangler/degrees-of-separation/graph"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 )
 
 func TestFetchEntityDecodesJSONCorrectly(t *testing.T) {
@@ -16,9 +20,9 @@ func TestFetchEntityDecodesJSONCorrectly(t *testing.T) {
 	"cast":[{"url":"cast-one","name":"Cast One","role":"Role Three"},{"url":"cast-two","name":"Cast Two","role":"Role Four"}]}`
 	server := serve(json)
 	defer server.Close()
-	baseURL = server.URL
+	client := NewClient(Config{BaseURL: server.URL})
 
-	e, _ := fetchEntity("a-node")
+	e, _ := client.fetchEntity(context.Background(), "a-node")
 	assert.Equal(t, "a-movie", e.URL)
 	assert.Equal(t, "Movie", e.Type)
 	assert.Equal(t, "A Movie", e.Name)
@@ -33,9 +37,9 @@ func TestFetchEntityDecodesJSONCorrectly(t *testing.T) {
 func TestFetchEntityReturnsNilOnHTTPError(t *testing.T) {
 	server := serve("", errors.New("A server error"), 500)
 	defer server.Close()
-	baseURL = server.URL
+	client := NewClient(Config{BaseURL: server.URL})
 
-	entity, err := fetchEntity("a-non-existent-node")
+	entity, err := client.fetchEntity(context.Background(), "a-non-existent-node")
 	assert.Nil(t, entity)
 	assert.Equal(t, "server error: 500: A server error\n", err.Error())
 }
@@ -45,10 +49,10 @@ func TestFetchMapsPersonConnectionsFromMovies(t *testing.T) {
 	"movies":[{"name":"Movie One","url":"movie-one","role":"Role One"},{"name":"Movie Two","url":"movie-two","role":"Role Two"}]}`
 	server := serve(json)
 	defer server.Close()
-	baseURL = server.URL
+	fetch := NewFetcher(NewClient(Config{BaseURL: server.URL}))
 
 	node := graph.NewNode("person-node")
-	Fetch(node)
+	fetch(context.Background(), node)
 	assert.True(t, node.IsNeighbour(&graph.Node{ID: "movie-one"}))
 	assert.True(t, node.IsNeighbour(&graph.Node{ID: "movie-two"}))
 }
@@ -58,10 +62,10 @@ func TestFetchMapsMovieConnectionsFromCast(t *testing.T) {
     "cast":[{"url":"cast-one","name":"Cast One","role":"Role Three"},{"url":"cast-two","name":"Cast Two","role":"Role Four"}]}`
 	server := serve(json)
 	defer server.Close()
-	baseURL = server.URL
+	fetch := NewFetcher(NewClient(Config{BaseURL: server.URL}))
 
 	node := graph.NewNode("movie-node")
-	Fetch(node)
+	fetch(context.Background(), node)
 	assert.True(t, node.IsNeighbour(&graph.Node{ID: "cast-one"}))
 	assert.True(t, node.IsNeighbour(&graph.Node{ID: "cast-two"}))
 }
@@ -87,3 +91,101 @@ func serve(json string, args ...interface{}) *httptest.Server {
 		fmt.Fprintln(w, json)
 	}))
 }
+
+func TestFetcherAbandonsRequestWhenContextIsDone(t *testing.T) {
+	server := serve(`{"url":"person-node","type":"Person","name":"An Actor"}`)
+	defer server.Close()
+	fetch := NewFetcher(NewClient(Config{BaseURL: server.URL}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	node := graph.NewNode("cancelled-node")
+	err := fetch(ctx, node)
+	assert.NotNil(t, err)
+	assert.False(t, node.HasData())
+}
+
+func TestClientSlowsDownWhenThrottled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL, RequestsPerSecond: 100, Burst: 1})
+	entity, err := client.fetchEntity(context.Background(), "a-throttled-node")
+	assert.Nil(t, entity)
+	assert.NotNil(t, err)
+	assert.Equal(t, 50.0, client.limiter.Rate())
+}
+
+func TestFetchEntityClassifiesMissingEntitiesAsPermanentErrors(t *testing.T) {
+	server := serve("", errors.New("Not found"), 404)
+	defer server.Close()
+	client := NewClient(Config{BaseURL: server.URL})
+
+	_, err := client.fetchEntity(context.Background(), "a-missing-node")
+	assert.True(t, graph.IsPermanent(err))
+
+	server = serve("", errors.New("Unavailable"), 503)
+	defer server.Close()
+	client = NewClient(Config{BaseURL: server.URL})
+
+	_, err = client.fetchEntity(context.Background(), "an-unavailable-node")
+	assert.False(t, graph.IsPermanent(err))
+}
+
+func TestClientServesCachedEntitiesWithoutRefetching(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintln(w, `{"url":"person-node","type":"Person","name":"An Actor"}`)
+	}))
+	defer server.Close()
+	dir, _ := ioutil.TempDir("", "moviebuff-cache")
+	defer os.RemoveAll(dir)
+
+	client := NewClient(Config{BaseURL: server.URL, Cache: NewDiskCache(dir, time.Hour)})
+	first, _ := client.fetchEntity(context.Background(), "person-node")
+	second, _ := client.fetchEntity(context.Background(), "person-node")
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, 1, client.Requests())
+	assert.Equal(t, first, second)
+
+	client = NewClient(Config{BaseURL: server.URL, Cache: NewDiskCache(dir, time.Nanosecond)})
+	var counted int64
+	client.fetchEntity(WithRequestCounter(context.Background(), &counted), "person-node")
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, int64(1), counted)
+}
+
+func TestClientRevalidatesExpiredEntitiesWithConditionalRequests(t *testing.T) {
+	lastModified := time.Date(2015, 2, 20, 10, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	downloads, revalidations := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == lastModified {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		fmt.Fprintln(w, `{"url":"person-node","type":"Person","name":"An Actor"}`)
+	}))
+	defer server.Close()
+	dir, _ := ioutil.TempDir("", "moviebuff-cache")
+	defer os.RemoveAll(dir)
+	cache := NewDiskCache(dir, time.Nanosecond)
+
+	client := NewClient(Config{BaseURL: server.URL, Cache: cache})
+	first, _ := client.fetchEntity(context.Background(), "person-node")
+	second, err := client.fetchEntity(context.Background(), "person-node")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, downloads)
+	assert.Equal(t, 1, revalidations)
+	assert.Equal(t, first, second)
+
+	entry, _ := cache.get("person-node")
+	assert.Equal(t, `"v1"`, entry.ETag)
+	assert.Equal(t, lastModified, entry.LastModified)
+}
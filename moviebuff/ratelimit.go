@@ -0,0 +1,136 @@
+package moviebuff
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// minRateFraction is the lowest fraction of the configured rate that throttling can slow a RateLimiter down to.
+	minRateFraction = 1.0 / 32
+	// recoveryFraction is the fraction of the configured rate regained after every successful request.
+	recoveryFraction = 1.0 / 20
+)
+
+// RateLimiter is a token bucket limiting how often requests are made.
+// It halves its rate whenever the server signals that it is throttling us, and slowly ramps back up as requests succeed.
+type RateLimiter struct {
+	maxRate      float64
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	lock         sync.Mutex
+}
+
+// NewRateLimiter creates a RateLimiter allowing requestsPerSecond requests on average, and bursts of up to burst requests.
+// A requestsPerSecond of 0 or less disables rate limiting, except for pauses requested by the server.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{maxRate: requestsPerSecond,
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now()}
+}
+
+// Wait blocks until a request may be made, or until the context is done.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := r.reserve()
+		if delay <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// reserve takes a token if one is available, or returns how long to wait before trying again.
+func (r *RateLimiter) reserve() time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := time.Now()
+	if now.Before(r.blockedUntil) {
+		return r.blockedUntil.Sub(now)
+	}
+	if r.maxRate <= 0 {
+		return 0
+	}
+
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+	return time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+}
+
+// Throttle slows the RateLimiter down after the server has rejected a request for being too frequent.
+// If retryAfter is positive, no requests are allowed until it has passed.
+func (r *RateLimiter) Throttle(retryAfter time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rate /= 2
+	if minRate := r.maxRate * minRateFraction; r.rate < minRate {
+		r.rate = minRate
+	}
+	r.tokens = 0
+	if until := time.Now().Add(retryAfter); retryAfter > 0 && until.After(r.blockedUntil) {
+		r.blockedUntil = until
+	}
+}
+
+// Recover ramps the RateLimiter's rate back up towards its configured rate after a successful request.
+func (r *RateLimiter) Recover() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rate += r.maxRate * recoveryFraction
+	if r.rate > r.maxRate {
+		r.rate = r.maxRate
+	}
+}
+
+// Rate returns the number of requests per second currently allowed.
+func (r *RateLimiter) Rate() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.rate
+}
+
+// isThrottled returns true if the response signals that the server is throttling us.
+func isThrottled(response *http.Response) bool {
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable
+}
+
+// retryAfter parses the Retry-After header of a response, which is either a number of seconds or an HTTP date.
+// Returns 0 if the header is missing or invalid.
+func retryAfter(response *http.Response) time.Duration {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
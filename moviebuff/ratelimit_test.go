@@ -0,0 +1,73 @@
+package moviebuff
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsBursts(t *testing.T) {
+	limiter := NewRateLimiter(1, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, limiter.Wait(context.Background()))
+	}
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+}
+
+func TestRateLimiterWaitsForTokensOnceBurstIsExhausted(t *testing.T) {
+	limiter := NewRateLimiter(20, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, limiter.Wait(context.Background()))
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+}
+
+func TestRateLimiterWaitStopsWhenContextIsDone(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, limiter.Wait(ctx))
+}
+
+func TestRateLimiterThrottlesAndRecovers(t *testing.T) {
+	limiter := NewRateLimiter(10, 1)
+	limiter.Throttle(0)
+	assert.Equal(t, 5.0, limiter.Rate())
+	for i := 0; i < 100; i++ {
+		limiter.Throttle(0)
+	}
+	assert.Equal(t, 10*minRateFraction, limiter.Rate())
+
+	for i := 0; i < 100; i++ {
+		limiter.Recover()
+	}
+	assert.Equal(t, 10.0, limiter.Rate())
+}
+
+func TestRateLimiterHonoursRetryAfter(t *testing.T) {
+	limiter := NewRateLimiter(0, 1)
+	limiter.Throttle(50 * time.Millisecond)
+	start := time.Now()
+	assert.Nil(t, limiter.Wait(context.Background()))
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestRetryAfterParsing(t *testing.T) {
+	response := &http.Response{Header: http.Header{}}
+	assert.Equal(t, time.Duration(0), retryAfter(response))
+
+	response.Header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryAfter(response))
+
+	response.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, retryAfter(response) > 50*time.Second)
+
+	response.Header.Set("Retry-After", "soon")
+	assert.Equal(t, time.Duration(0), retryAfter(response))
+}
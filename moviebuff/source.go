@@ -0,0 +1,121 @@
+package moviebuff
+
+import (
+	"context"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"sort"
+)
+
+// Source provides Moviebuff entities by their ID/URL slug.
+// The Moviebuff HTTP Client is one Source, but entities could just as well come from local dumps, or from a mock.
+type Source interface {
+	Entity(ctx context.Context, id string) (*Entity, error)
+}
+
+// Entity is a Moviebuff Person or Movie, along with the entities it is connected to.
+type Entity struct {
+	ID         string
+	Name       string
+	Type       string
+	Neighbours []Neighbour
+}
+
+// Neighbour is an entity connected to another Entity, along with the role that connects them.
+// An entity connected to another in several roles is listed as a Neighbour once for each role.
+type Neighbour struct {
+	ID   string
+	Name string
+	Role string
+}
+
+// Credit is the metadata attached to the Edge between a Person and a Movie: the roles the Person had in the Movie.
+// Roles are sorted, and merged with those of any other Credit for the same Edge.
+type Credit struct {
+	Person     string
+	PersonName string
+	Movie      string
+	MovieName  string
+	Roles      []string
+}
+
+// Merge returns a Credit with the roles of both Credits, when another Credit is attached to the same Edge.
+func (c Credit) Merge(other interface{}) interface{} {
+	credit, isCredit := other.(Credit)
+	if !isCredit {
+		return c
+	}
+	merged := c
+	merged.Roles = mergeRoles(c.Roles, credit.Roles)
+	if merged.PersonName == "" {
+		merged.PersonName = credit.PersonName
+	}
+	if merged.MovieName == "" {
+		merged.MovieName = credit.MovieName
+	}
+	return merged
+}
+
+// mergeRoles returns the sorted union of two lists of roles.
+func mergeRoles(roles, others []string) []string {
+	merged := []string{}
+	seen := make(map[string]bool)
+	for _, role := range append(append([]string{}, roles...), others...) {
+		if !seen[role] {
+			seen[role] = true
+			merged = append(merged, role)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// entity converts a decoded Moviebuff JSON entity into an Entity.
+// People are connected to their movies, and movies are connected to their cast, but connections are taken from both
+// the movies and cast arrays, whichever are present. A connection listed in both arrays with the same role is only
+// included once, but a connection listed with several roles is included once for each of them.
+func (e *mbEntity) entity() *Entity {
+	entity := &Entity{ID: e.URL, Name: e.Name, Type: e.Type, Neighbours: []Neighbour{}}
+	seen := make(map[[2]string]bool)
+	for _, connections := range [][]mbConnection{e.Movies, e.Cast} {
+		for _, connection := range connections {
+			if seen[[2]string{connection.URL, connection.Role}] {
+				continue
+			}
+			seen[[2]string{connection.URL, connection.Role}] = true
+			entity.Neighbours = append(entity.Neighbours, Neighbour{ID: connection.URL, Name: connection.Name, Role: connection.Role})
+		}
+	}
+	return entity
+}
+
+// credit returns the Credit connecting the Entity to one of its Neighbours.
+func (e *Entity) credit(neighbour Neighbour) Credit {
+	if e.Type == "Person" {
+		return Credit{Person: e.ID, PersonName: e.Name, Movie: neighbour.ID, MovieName: neighbour.Name, Roles: []string{neighbour.Role}}
+	}
+	return Credit{Person: neighbour.ID, PersonName: neighbour.Name, Movie: e.ID, MovieName: e.Name, Roles: []string{neighbour.Role}}
+}
+
+// NewFetcher returns a ContextNodeFetcher that loads Nodes with Entities from the given Source,
+// and connects them to Nodes for their Neighbours, which belong to the same NodeGroup and in turn load from the same Source.
+// Every Edge is labelled with the Credit connecting its two Nodes, and weighed by the strongest role in it.
+// Parameter 1: weigh - RoleWeigher to weigh Edges with. Defaults to DefaultRoleWeigher.
+func NewFetcher(source Source, args ...interface{}) graph.ContextNodeFetcher {
+	weigh := RoleWeigher(DefaultRoleWeigher)
+	if len(args) > 0 && args[0] != nil {
+		weigh = args[0].(RoleWeigher)
+	}
+	return func(ctx context.Context, n *graph.Node) error {
+		entity, err := source.Entity(ctx, n.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, neighbour := range entity.Neighbours {
+			n.NewNeighbour(neighbour.ID, entity.credit(neighbour), weigh(neighbour.Role))
+		}
+		// Only mark the Node as loaded once all of its neighbours are connected
+		n.SetData(entity)
+		return nil
+	}
+}
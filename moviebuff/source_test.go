@@ -0,0 +1,150 @@
+package moviebuff
+
+import (
+	"context"
+	"errors"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type mockSource map[string]*Entity
+
+func (s mockSource) Entity(ctx context.Context, id string) (*Entity, error) {
+	entity, present := s[id]
+	if !present {
+		return nil, graph.Permanent(errors.New("not found: " + id))
+	}
+	return entity, nil
+}
+
+func TestEntityConversionConnectsPeopleToMoviesAndMoviesToCast(t *testing.T) {
+	person := &mbEntity{URL: "an-actor", Name: "An Actor", Type: "Person",
+		Movies: []mbConnection{mbConnection{URL: "a-movie", Name: "A Movie", Role: "Actor"}}}
+	assert.Equal(t, &Entity{ID: "an-actor", Name: "An Actor", Type: "Person",
+		Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Actor"}}}, person.entity())
+
+	movie := &mbEntity{URL: "a-movie", Name: "A Movie", Type: "Movie",
+		Cast: []mbConnection{mbConnection{URL: "an-actor", Name: "An Actor", Role: "Actor"}}}
+	assert.Equal(t, &Entity{ID: "a-movie", Name: "A Movie", Type: "Movie",
+		Neighbours: []Neighbour{Neighbour{ID: "an-actor", Name: "An Actor", Role: "Actor"}}}, movie.entity())
+}
+
+func TestFetcherLoadsNodesFromAnySource(t *testing.T) {
+	source := mockSource{
+		"an-actor": &Entity{ID: "an-actor", Name: "An Actor", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Actor"}}},
+		"a-movie": &Entity{ID: "a-movie", Name: "A Movie", Type: "Movie",
+			Neighbours: []Neighbour{Neighbour{ID: "an-actor", Name: "An Actor", Role: "Actor"},
+				Neighbour{ID: "a-director", Name: "A Director", Role: "Director"}}},
+		"a-director": &Entity{ID: "a-director", Name: "A Director", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Director"}}},
+	}
+	group := graph.NewNodeGroup()
+	fetch := NewFetcher(source)
+	actor := graph.NewNode("an-actor", fetch, group)
+	director := graph.NewNode("a-director", fetch, group)
+
+	paths := actor.BidirectionalPathsTo(director)
+	assert.Equal(t, 1, len(paths))
+	if len(paths) == 1 {
+		assert.Equal(t, "an-actor -> a-movie -> a-director", paths[0].String())
+	}
+
+	missing := graph.NewNode("a-missing-person", fetch, group)
+	assert.True(t, graph.IsPermanent(fetch(context.Background(), missing)))
+}
+
+func TestEntityConversionTakesConnectionsFromBothMoviesAndCast(t *testing.T) {
+	movie := &mbEntity{URL: "a-movie", Name: "A Movie", Type: "Movie",
+		Movies: []mbConnection{mbConnection{URL: "an-actor", Name: "An Actor", Role: "Actor"}},
+		Cast: []mbConnection{mbConnection{URL: "an-actor", Name: "An Actor", Role: "Actor"},
+			mbConnection{URL: "a-director", Name: "A Director", Role: "Director"}}}
+	assert.Equal(t, []Neighbour{Neighbour{ID: "an-actor", Name: "An Actor", Role: "Actor"},
+		Neighbour{ID: "a-director", Name: "A Director", Role: "Director"}}, movie.entity().Neighbours)
+}
+
+func TestEntityConversionKeepsEveryRoleOfAConnection(t *testing.T) {
+	person := &mbEntity{URL: "an-auteur", Name: "An Auteur", Type: "Person",
+		Movies: []mbConnection{mbConnection{URL: "a-movie", Name: "A Movie", Role: "Director"},
+			mbConnection{URL: "a-movie", Name: "A Movie", Role: "Actor"}}}
+	assert.Equal(t, []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Director"},
+		Neighbour{ID: "a-movie", Name: "A Movie", Role: "Actor"}}, person.entity().Neighbours)
+}
+
+func TestFetcherMergesEveryRoleOfAPersonInAMovie(t *testing.T) {
+	source := mockSource{
+		"an-auteur": &Entity{ID: "an-auteur", Name: "An Auteur", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Director"},
+				Neighbour{ID: "a-movie", Name: "A Movie", Role: "Cameo"}}},
+		"a-movie": &Entity{ID: "a-movie", Name: "A Movie", Type: "Movie",
+			Neighbours: []Neighbour{Neighbour{ID: "an-auteur", Name: "An Auteur", Role: "Actor"}}},
+	}
+	// The Edge must end up the same whichever end is loaded first
+	for _, order := range [][]string{{"an-auteur", "a-movie"}, {"a-movie", "an-auteur"}} {
+		group := graph.NewNodeGroup()
+		fetch := NewFetcher(source)
+		for _, id := range order {
+			fetch(context.Background(), graph.NewNode(id, fetch, group))
+		}
+		person, _ := group.Get("an-auteur")
+		movie, _ := group.Get("a-movie")
+
+		for _, edge := range []graph.Edge{edgeBetween(person, movie), edgeBetween(movie, person)} {
+			assert.Equal(t, Credit{Person: "an-auteur", PersonName: "An Auteur", Movie: "a-movie", MovieName: "A Movie",
+				Roles: []string{"Actor", "Cameo", "Director"}}, edge.Data)
+			assert.Equal(t, 1.0, edge.Weight)
+		}
+	}
+}
+
+// edgeBetween returns the Edge from one Node to another.
+func edgeBetween(from, to *graph.Node) graph.Edge {
+	edge, _ := from.EdgeTo(to)
+	return edge
+}
+
+func TestFetcherLabelsEdgesWithCredits(t *testing.T) {
+	source := mockSource{
+		"a-supporting-actor": &Entity{ID: "a-supporting-actor", Name: "A Supporting Actor", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-period-drama", Name: "A Period Drama", Role: "Supporting Actor"}}},
+		"a-period-drama": &Entity{ID: "a-period-drama", Name: "A Period Drama", Type: "Movie",
+			Neighbours: []Neighbour{Neighbour{ID: "an-auteur", Name: "An Auteur", Role: "Director"}}},
+	}
+	group := graph.NewNodeGroup()
+	fetch := NewFetcher(source)
+	actor := graph.NewNode("a-supporting-actor", fetch, group)
+	movie := graph.NewNode("a-period-drama", fetch, group)
+	fetch(context.Background(), actor)
+	fetch(context.Background(), movie)
+
+	edge, connected := actor.EdgeTo(movie)
+	assert.True(t, connected)
+	assert.Equal(t, Credit{Person: "a-supporting-actor", PersonName: "A Supporting Actor", Movie: "a-period-drama", MovieName: "A Period Drama", Roles: []string{"Supporting Actor"}}, edge.Data)
+
+	edge, connected = movie.EdgeTo(actor)
+	assert.True(t, connected)
+	assert.Equal(t, "Supporting Actor", edge.Data.(Credit).Roles[0])
+
+	edge, connected = movie.EdgeTo(&graph.Node{ID: "an-auteur"})
+	assert.True(t, connected)
+	assert.Equal(t, Credit{Person: "an-auteur", PersonName: "An Auteur", Movie: "a-period-drama", MovieName: "A Period Drama", Roles: []string{"Director"}}, edge.Data)
+}
+
+func TestFetcherCreatesNeighboursInTheSameNodeGroup(t *testing.T) {
+	source := mockSource{
+		"an-actor": &Entity{ID: "an-actor", Name: "An Actor", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-movie", Name: "A Movie", Role: "Actor"}}},
+	}
+	group := graph.NewNodeGroup()
+	fetch := NewFetcher(source)
+	actor := graph.NewNode("an-actor", fetch, group)
+	fetch(context.Background(), actor)
+
+	movie, present := group.Get("a-movie")
+	assert.True(t, present)
+	if present {
+		assert.Equal(t, group, movie.Group())
+		assert.True(t, actor.IsNeighbour(movie))
+	}
+}
@@ -0,0 +1,58 @@
+package moviebuff
+
+import (
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"strings"
+)
+
+// RoleWeigher weighs the Edge between a Person and a Movie by the role the Person had in it.
+// Lower weights are stronger connections, so weighted searches prefer chains through them.
+type RoleWeigher func(role string) float64
+
+// DefaultRoleWeigher weighs lead actors and directors as the strongest connections, and cameos, guest appearances and
+// uncredited roles as the weakest. Supporting roles are in between, and other crew roles are just stronger than those.
+func DefaultRoleWeigher(role string) float64 {
+	role = strings.ToLower(role)
+	for _, weak := range []string{"uncredited", "cameo", "guest", "special appearance"} {
+		if strings.Contains(role, weak) {
+			return 8
+		}
+	}
+	switch {
+	case strings.Contains(role, "supporting"):
+		return 3
+	case role == "actor", role == "actress", role == "lead actor", role == "lead actress", role == "director":
+		return 1
+	}
+	return 2
+}
+
+// WeighRoles returns a RoleWeigher that looks roles up in the given weights, ignoring case, and falls back to
+// DefaultRoleWeigher for roles that aren't in it.
+func WeighRoles(weights map[string]float64) RoleWeigher {
+	lowerCased := make(map[string]float64)
+	for role, weight := range weights {
+		lowerCased[strings.ToLower(role)] = weight
+	}
+	return func(role string) float64 {
+		if weight, present := lowerCased[strings.ToLower(role)]; present {
+			return weight
+		}
+		return DefaultRoleWeigher(role)
+	}
+}
+
+// NewHeuristic returns a graph.Heuristic for A* searches between people, given the lowest weight any role can have.
+// Every Person other than the target is at least two Edges away from it, through a Movie, and every Movie is at least
+// one Edge away, so the heuristic never overestimates. Nodes that haven't been loaded yet are assumed to be Movies.
+func NewHeuristic(minWeight float64) graph.Heuristic {
+	return func(node, target *graph.Node) float64 {
+		if node.Equal(target) {
+			return 0
+		}
+		if entity, loaded := node.Data().(*Entity); loaded && entity.Type == "Person" {
+			return 2 * minWeight
+		}
+		return minWeight
+	}
+}
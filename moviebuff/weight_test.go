@@ -0,0 +1,64 @@
+package moviebuff
+
+import (
+	"context"
+	"github.com/CodeMangler/degrees-of-separation/graph"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDefaultRoleWeigherPrefersLeadRoles(t *testing.T) {
+	assert.Equal(t, 1.0, DefaultRoleWeigher("Actor"))
+	assert.Equal(t, 1.0, DefaultRoleWeigher("Director"))
+	assert.Equal(t, 2.0, DefaultRoleWeigher("Music Director"))
+	assert.Equal(t, 3.0, DefaultRoleWeigher("Supporting Actor"))
+	assert.Equal(t, 8.0, DefaultRoleWeigher("Cameo"))
+	assert.Equal(t, 8.0, DefaultRoleWeigher("Actor (Uncredited)"))
+}
+
+func TestWeighRolesFallsBackToTheDefaultWeights(t *testing.T) {
+	weigh := WeighRoles(map[string]float64{"Producer": 0.5, "actor": 4})
+	assert.Equal(t, 0.5, weigh("producer"))
+	assert.Equal(t, 4.0, weigh("Actor"))
+	assert.Equal(t, 8.0, weigh("Cameo"))
+}
+
+func TestFetcherWeighsEdgesByRole(t *testing.T) {
+	source := mockSource{
+		"a-lead": &Entity{ID: "a-lead", Name: "A Lead", Type: "Person",
+			Neighbours: []Neighbour{Neighbour{ID: "a-blockbuster", Name: "A Blockbuster", Role: "Actor"},
+				Neighbour{ID: "a-flop", Name: "A Flop", Role: "Cameo"}}},
+	}
+	group := graph.NewNodeGroup()
+	lead := graph.NewNode("a-lead", NewFetcher(source), group)
+	lead.Group().Loader().Load(context.Background(), lead)
+
+	blockbuster, _ := group.Get("a-blockbuster")
+	edge, _ := lead.EdgeTo(blockbuster)
+	assert.Equal(t, 1.0, edge.Weight)
+	flop, _ := group.Get("a-flop")
+	edge, _ = flop.EdgeTo(lead)
+	assert.Equal(t, 8.0, edge.Weight)
+
+	group = graph.NewNodeGroup()
+	lead = graph.NewNode("a-lead", NewFetcher(source, RoleWeigher(func(role string) float64 { return 2 })), group)
+	lead.Group().Loader().Load(context.Background(), lead)
+	for _, edge := range lead.Edges() {
+		assert.Equal(t, 2.0, edge.Weight)
+	}
+}
+
+func TestHeuristicNeverOverestimates(t *testing.T) {
+	group := graph.NewNodeGroup()
+	person := graph.NewNode("a-person", nil, group)
+	movie := graph.NewNode("a-movie", nil, group)
+	target := graph.NewNode("a-target", nil, group)
+	person.SetData(&Entity{ID: "a-person", Type: "Person"})
+	movie.SetData(&Entity{ID: "a-movie", Type: "Movie"})
+
+	heuristic := NewHeuristic(1.5)
+	assert.Equal(t, 3.0, heuristic(person, target))
+	assert.Equal(t, 1.5, heuristic(movie, target))
+	assert.Equal(t, 1.5, heuristic(graph.NewNode("unloaded", nil, group), target))
+	assert.Equal(t, 0.0, heuristic(target, target))
+}